@@ -12,11 +12,14 @@ import (
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/client/input"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/ojo-network/cw-relayer/config"
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
 	"github.com/ojo-network/cw-relayer/relayer"
 	relayerclient "github.com/ojo-network/cw-relayer/relayer/client"
 )
@@ -45,6 +48,7 @@ func init() {
 	rootCmd.PersistentFlags().String(flagLogFormat, logLevelText, "logging format; must be either json or text")
 
 	rootCmd.AddCommand(getVersionCmd())
+	rootCmd.AddCommand(getSignerCmd())
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -117,17 +121,145 @@ func cwRelayerCmdHandler(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse Query timeout: %w", err)
 	}
 
+	queryBackoff, err := time.ParseDuration(cfg.QueryBackoff)
+	if err != nil {
+		return fmt.Errorf("failed to parse Query backoff: %w", err)
+	}
+
 	resolveDuration, err := time.ParseDuration(cfg.ResolveDuration)
 	if err != nil {
 		return fmt.Errorf("failed to parse Resolve Duration: %w", err)
 	}
 
-	// Gather pass via env variable || std input
-	keyringPass, err := getKeyringPassword()
+	quorumTolerance := sdk.ZeroDec()
+	if len(cfg.Quorum.Tolerance) > 0 {
+		quorumTolerance, err = sdk.NewDecFromStr(cfg.Quorum.Tolerance)
+		if err != nil {
+			return fmt.Errorf("failed to parse Quorum tolerance: %w", err)
+		}
+	}
+
+	schedule := relayer.ScheduleConfig{Clock: cfg.Schedule.Clock, FromParams: cfg.Schedule.FromParams}
+	schedule.ParamsRefresh, err = time.ParseDuration(cfg.Schedule.ParamsRefresh)
+	if err != nil {
+		return fmt.Errorf("failed to parse Params refresh: %w", err)
+	}
+
+	if len(cfg.Schedule.MedianInterval) > 0 {
+		schedule.MedianInterval, err = time.ParseDuration(cfg.Schedule.MedianInterval)
+		if err != nil {
+			return fmt.Errorf("failed to parse Median interval: %w", err)
+		}
+	}
+
+	if len(cfg.Schedule.DeviationInterval) > 0 {
+		schedule.DeviationInterval, err = time.ParseDuration(cfg.Schedule.DeviationInterval)
+		if err != nil {
+			return fmt.Errorf("failed to parse Deviation interval: %w", err)
+		}
+	}
+
+	staleness := relayer.StalenessConfig{MaxUnchangedTicks: cfg.Staleness.MaxUnchangedTicks}
+	if len(cfg.Staleness.MaxAge) > 0 {
+		staleness.MaxAge, err = time.ParseDuration(cfg.Staleness.MaxAge)
+		if err != nil {
+			return fmt.Errorf("failed to parse Staleness max age: %w", err)
+		}
+	}
+
+	outliers := relayer.OutlierConfig{K: sdk.ZeroDec(), Action: cfg.Outliers.Action}
+	if len(cfg.Outliers.K) > 0 {
+		outliers.K, err = sdk.NewDecFromStr(cfg.Outliers.K)
+		if err != nil {
+			return fmt.Errorf("failed to parse Outlier filter k: %w", err)
+		}
+	}
+
+	derived := relayer.DerivedConfig{Warmup: cfg.DerivedSeriesWarmup}
+	for _, series := range cfg.DerivedSeries {
+		window, err := time.ParseDuration(series.Window)
+		if err != nil {
+			return fmt.Errorf("failed to parse Derived series window: %w", err)
+		}
+
+		derived.Series = append(derived.Series, relayer.SeriesConfig{
+			Denoms: series.Denoms,
+			Kind:   series.Kind,
+			Window: window,
+			Suffix: series.Suffix,
+		})
+	}
+
+	var synthetic []relayer.SyntheticSymbol
+	for _, symbol := range cfg.SyntheticSymbols {
+		syntheticSymbol, err := relayer.NewSyntheticSymbol(symbol.Symbol, symbol.Expression)
+		if err != nil {
+			return err
+		}
+
+		synthetic = append(synthetic, syntheticSymbol)
+	}
+
+	verifyTimeout, err := time.ParseDuration(cfg.Verify.Timeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse Verify timeout: %w", err)
+	}
+
+	alerts, err := newAlertConfig(logger, cfg.Alerts)
+	if err != nil {
+		return err
+	}
+
+	feeGrant := relayer.FeeGrantConfig{Fallback: cfg.FeeGrant.Fallback}
+	feeGrant.Interval, err = time.ParseDuration(cfg.FeeGrant.Interval)
+	if err != nil {
+		return fmt.Errorf("failed to parse Fee grant interval: %w", err)
+	}
+
+	feeGrant.ExpiryWarning, err = time.ParseDuration(cfg.FeeGrant.ExpiryWarning)
+	if err != nil {
+		return fmt.Errorf("failed to parse Fee grant expiry warning: %w", err)
+	}
+
+	feeGrant.SpendWarning, err = sdk.ParseCoinsNormalized(cfg.FeeGrant.SpendWarning)
+	if err != nil {
+		return fmt.Errorf("failed to parse Fee grant spend warning: %w", err)
+	}
+
+	// relay txs are signed by the keyring key unless a remote signer is set,
+	// the keyring is only used for top ups with remote signers
+	var remoteSigner endpoint.Endpoint
+	if len(cfg.Signer.Remote) > 0 {
+		remoteSigner = cfg.Endpoint(cfg.Signer.Remote)
+	}
+
+	var multisig relayerclient.MultisigConfig
+	if cfg.Signer.Multisig != nil {
+		multisig.Threshold = cfg.Signer.Multisig.Threshold
+		for _, member := range cfg.Signer.Multisig.Members {
+			var remote endpoint.Endpoint
+			if len(member.Remote) > 0 {
+				remote = cfg.Endpoint(member.Remote)
+			}
+
+			multisig.Members = append(multisig.Members, relayerclient.MultisigMember{Address: member.Address, Remote: remote})
+		}
+	}
+
+	profile, err := relayerclient.GetChainProfile(cfg.Account.Profile)
 	if err != nil {
 		return err
 	}
 
+	// Gather pass via env variable || std input
+	var keyringPass string
+	if cfg.Signer.UsesKeyring() || cfg.Balance.TopUp != nil {
+		keyringPass, err = getKeyringPassword()
+		if err != nil {
+			return err
+		}
+	}
+
 	// client for interacting with the ojo & wasmd chain
 	client, err := relayerclient.NewRelayerClient(
 		ctx,
@@ -136,26 +268,55 @@ func cwRelayerCmdHandler(cmd *cobra.Command, args []string) error {
 		cfg.Keyring.Backend,
 		cfg.Keyring.Dir,
 		keyringPass,
-		cfg.RPC.TMRPCEndpoint,
-		cfg.RPC.QueryEndpoint,
+		cfg.Endpoint(cfg.RPC.TMRPCEndpoint),
+		cfg.Endpoint(cfg.RPC.QueryEndpoint),
 		rpcTimeout,
 		cfg.Account.Address,
 		cfg.Account.AccPrefix,
+		profile,
 		cfg.GasAdjustment,
 		cfg.GasPrices,
 		cfg.FeeGrant.Granter,
+		cfg.Authz.Granter,
+		remoteSigner,
+		multisig,
 	)
 	if err != nil {
 		return err
 	}
 
+	// addresses are decoded with the account prefix of the client
+	balance, err := newBalanceConfig(cfg.Balance, client.AddressCodec)
+	if err != nil {
+		return err
+	}
+
+	triggers := make([]relayerclient.EventTrigger, len(cfg.EventTriggers))
+	for i, trigger := range cfg.EventTriggers {
+		attributes := make(map[string][]string, len(trigger.Attributes))
+		for _, attribute := range trigger.Attributes {
+			attributes[attribute.Key] = append(attributes[attribute.Key], attribute.Values...)
+		}
+
+		triggers[i] = relayerclient.EventTrigger{
+			EventType:  trigger.EventType,
+			Attributes: attributes,
+			Profile:    trigger.Profile,
+		}
+	}
+
+	profiles := make([]relayer.RelayProfile, len(cfg.RelayProfiles))
+	for i, profile := range cfg.RelayProfiles {
+		profiles[i] = relayer.RelayProfile{Name: profile.Name, Denoms: profile.Denoms}
+	}
+
 	// subscribe to new block heights
 	tick, err := relayerclient.NewBlockHeightSubscription(
 		ctx,
-		cfg.EventRPCS,
+		cfg.EndpointList(cfg.EventRPCS),
 		eventTimeout,
 		maxTickTimeout,
-		cfg.TickEventType,
+		triggers,
 		logger,
 		cfg.Restart.SkipError,
 		cfg.MaxRetries,
@@ -164,26 +325,57 @@ func cwRelayerCmdHandler(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	newRelayer := relayer.New(
+	// persistent connections to the ojo query rpcs
+	pool, err := relayerclient.NewEndpointPool(
 		logger,
-		client,
-		cfg.ContractAddress,
-		cfg.TimeoutHeight,
-		cfg.MissedThreshold,
+		cfg.EndpointList(cfg.QueryRPCS),
+		cfg.EndpointList(cfg.FallbackRPCS),
 		cfg.MaxRetries,
-		cfg.MedianDuration,
-		cfg.DeviationDuration,
-		cfg.SkipNumEvents,
-		cfg.IgnoreMedianErrors,
-		resolveDuration,
-		queryTimeout,
-		cfg.RequestID,
-		cfg.MedianRequestID,
-		cfg.DeviationRequestID,
-		relayer.AutoRestartConfig{AutoRestart: cfg.Restart.AutoID, Denom: cfg.Restart.Denom, SkipError: cfg.Restart.SkipError},
-		tick.Tick,
-		cfg.QueryRPCS,
+		queryBackoff,
 	)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	newRelayer := relayer.New(logger, client, pool, tick.Tick, relayer.Config{
+		ContractAddress:    cfg.ContractAddress,
+		TimeoutHeight:      cfg.TimeoutHeight,
+		MissedThreshold:    cfg.MissedThreshold,
+		MedianDuration:     cfg.MedianDuration,
+		DeviationDuration:  cfg.DeviationDuration,
+		SkipNumEvents:      cfg.SkipNumEvents,
+		IgnoreMedianErrors: cfg.IgnoreMedianErrors,
+		EventRates:         cfg.EventRates,
+		ResolveDuration:    resolveDuration,
+		QueryTimeout:       queryTimeout,
+		RequestID:          cfg.RequestID,
+		MedianRequestID:    cfg.MedianRequestID,
+		DeviationRequestID: cfg.DeviationRequestID,
+		AutoRestart: relayer.AutoRestartConfig{
+			AutoRestart: cfg.Restart.AutoID,
+			Denom:       cfg.Restart.Denom,
+			SkipError:   cfg.Restart.SkipError,
+		},
+		Profiles:    profiles,
+		Quorum:      relayer.QuorumConfig{Size: cfg.Quorum.Size, Tolerance: quorumTolerance},
+		StampFormat: relayer.StampFormat(cfg.StampFormat),
+		Window:      relayer.HistoryWindow{Size: cfg.HistoryWindow, Denoms: cfg.HistoryWindows},
+		Schedule:    schedule,
+		Completeness: relayer.CompletenessConfig{
+			ExpectedDenoms: cfg.Completeness.ExpectedDenoms,
+			AcceptList:     cfg.Completeness.AcceptList,
+			Policy:         cfg.Completeness.Policy,
+		},
+		Staleness: staleness,
+		Outliers:  outliers,
+		Derived:   derived,
+		Synthetic: synthetic,
+		Verify:    relayer.VerifyConfig{Enabled: cfg.Verify.Enabled, Timeout: verifyTimeout},
+		Alerts:    alerts,
+		Balance:   balance,
+		FeeGrant:  feeGrant,
+	})
 
 	g.Go(
 		func() error {
@@ -197,6 +389,103 @@ func cwRelayerCmdHandler(cmd *cobra.Command, args []string) error {
 	return g.Wait()
 }
 
+// newAlertConfig returns the relayer alert config with an alerter sending to the configured sinks,
+// the alerter is nil if no sinks are configured.
+func newAlertConfig(logger zerolog.Logger, cfg config.AlertConfig) (relayer.AlertConfig, error) {
+	switchStormWindow, err := time.ParseDuration(cfg.SwitchStormWindow)
+	if err != nil {
+		return relayer.AlertConfig{}, fmt.Errorf("failed to parse switch storm window: %w", err)
+	}
+
+	alerts := relayer.AlertConfig{
+		SwitchStormThreshold: cfg.SwitchStormThreshold,
+		SwitchStormWindow:    switchStormWindow,
+	}
+	if len(cfg.Sinks) == 0 {
+		return alerts, nil
+	}
+
+	cooldown, err := time.ParseDuration(cfg.Cooldown)
+	if err != nil {
+		return relayer.AlertConfig{}, fmt.Errorf("failed to parse alert cooldown: %w", err)
+	}
+
+	dedup, err := time.ParseDuration(cfg.DedupWindow)
+	if err != nil {
+		return relayer.AlertConfig{}, fmt.Errorf("failed to parse alert dedup window: %w", err)
+	}
+
+	sinks := make([]alert.Sink, len(cfg.Sinks))
+	for i, sinkConfig := range cfg.Sinks {
+		sink, err := alert.NewSink(logger, sinkConfig.Type, sinkConfig.URL)
+		if err != nil {
+			return relayer.AlertConfig{}, err
+		}
+
+		sinks[i] = sink
+	}
+
+	alerts.Alerter = alert.NewAlerter(logger, sinks, cooldown, dedup)
+	return alerts, nil
+}
+
+// newBalanceConfig returns the relayer balance monitoring config.
+func newBalanceConfig(cfg config.BalanceConfig, codec relayerclient.Bech32Codec) (relayer.BalanceConfig, error) {
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return relayer.BalanceConfig{}, fmt.Errorf("failed to parse Balance interval: %w", err)
+	}
+
+	balance := relayer.BalanceConfig{Denom: cfg.Denom, Interval: interval}
+	if balance.Warning, err = parseInt(cfg.Warning); err != nil {
+		return relayer.BalanceConfig{}, fmt.Errorf("failed to parse Balance warning: %w", err)
+	}
+
+	if balance.Critical, err = parseInt(cfg.Critical); err != nil {
+		return relayer.BalanceConfig{}, fmt.Errorf("failed to parse Balance critical: %w", err)
+	}
+
+	if cfg.TopUp == nil {
+		return balance, nil
+	}
+
+	if balance.TopUp.Funder, err = codec.Decode(cfg.TopUp.Funder); err != nil {
+		return relayer.BalanceConfig{}, fmt.Errorf("failed to parse Top up funder: %w", err)
+	}
+
+	if balance.TopUp.Floor, err = parseInt(cfg.TopUp.Floor); err != nil {
+		return relayer.BalanceConfig{}, fmt.Errorf("failed to parse Top up floor: %w", err)
+	}
+
+	if balance.TopUp.Amount, err = parseInt(cfg.TopUp.Amount); err != nil {
+		return relayer.BalanceConfig{}, fmt.Errorf("failed to parse Top up amount: %w", err)
+	}
+
+	if balance.TopUp.DailyCap, err = parseInt(cfg.TopUp.DailyCap); err != nil {
+		return relayer.BalanceConfig{}, fmt.Errorf("failed to parse Top up daily cap: %w", err)
+	}
+
+	if !balance.TopUp.DailyCap.IsPositive() {
+		return relayer.BalanceConfig{}, fmt.Errorf("top up daily cap must be positive")
+	}
+
+	return balance, nil
+}
+
+// parseInt parses an integer amount, an empty amount is returned as a nil Int.
+func parseInt(amount string) (sdk.Int, error) {
+	if len(amount) == 0 {
+		return sdk.Int{}, nil
+	}
+
+	i, ok := sdk.NewIntFromString(amount)
+	if !ok {
+		return sdk.Int{}, fmt.Errorf("invalid integer %s", amount)
+	}
+
+	return i, nil
+}
+
 func getKeyringPassword() (string, error) {
 	reader := bufio.NewReader(os.Stdin)
 
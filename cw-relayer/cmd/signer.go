@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+
+	"github.com/ojo-network/cw-relayer/pkg/signer"
+	relayerclient "github.com/ojo-network/cw-relayer/relayer/client"
+)
+
+const (
+	flagKeyringBackend = "keyring-backend"
+	flagKeyringDir     = "keyring-dir"
+	flagAccPrefix      = "acc-prefix"
+	flagProfile        = "profile"
+
+	envVariableSignerToken = "CW_SIGNER_TOKEN"
+
+	signerReadTimeout = 10 * time.Second
+)
+
+func getSignerCmd() *cobra.Command {
+	signerCmd := &cobra.Command{
+		Use:   "signer [listen-address]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Start a reference remote signer serving the keys of a local keyring",
+		Long: `Start a reference remote signer serving the keys of a local keyring over http, meant for local tests.
+	The listen address is a host:port or a unix:// socket path. Requests must carry the bearer token in
+	CW_SIGNER_TOKEN if it is set, it is required unless the signer listens on a loopback address or a unix socket.`,
+		RunE: signerCmdHandler,
+	}
+
+	signerCmd.Flags().String(flagKeyringBackend, keyring.BackendTest, "keyring backend")
+	signerCmd.Flags().String(flagKeyringDir, "", "keyring directory")
+	signerCmd.Flags().String(flagAccPrefix, sdk.Bech32MainPrefix, "bech32 account address prefix")
+	signerCmd.Flags().String(flagProfile, relayerclient.ProfileWasm, "chain profile of the keys, wasm or ethermint")
+
+	return signerCmd
+}
+
+func signerCmdHandler(cmd *cobra.Command, args []string) error {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
+
+	backend, err := cmd.Flags().GetString(flagKeyringBackend)
+	if err != nil {
+		return err
+	}
+
+	dir, err := cmd.Flags().GetString(flagKeyringDir)
+	if err != nil {
+		return err
+	}
+
+	accPrefix, err := cmd.Flags().GetString(flagAccPrefix)
+	if err != nil {
+		return err
+	}
+
+	profileName, err := cmd.Flags().GetString(flagProfile)
+	if err != nil {
+		return err
+	}
+
+	profile, err := relayerclient.GetChainProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	var keyringInput io.Reader = os.Stdin
+	if backend != keyring.BackendTest && backend != keyring.BackendMemory {
+		pass, err := getKeyringPassword()
+		if err != nil {
+			return err
+		}
+
+		keyringInput = relayerclient.NewPassReader(pass)
+	}
+
+	encoding := relayerclient.MakeEncodingConfig(profile)
+	kr, err := keyring.New("signer", backend, dir, keyringInput, encoding.Marshaler, profile.KeyringOptions()...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	trapSignal(cancel, logger)
+
+	token := os.Getenv(envVariableSignerToken)
+	listener, err := signer.Listen(args[0], token)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:           signer.NewServer(logger, kr, accPrefix, token),
+		ReadHeaderTimeout: signerReadTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+
+	logger.Info().Str("address", args[0]).Msg("starting remote signer...")
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("remote signer failed: %w", err)
+	}
+
+	return nil
+}
@@ -3,10 +3,13 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
 )
 
 const (
@@ -16,7 +19,19 @@ const (
 	defaultTimeout         = 1 * time.Minute
 	defaultResolveDuration = 2 * time.Second
 	defaultRetries         = 1
+	defaultQueryBackoff    = 200 * time.Millisecond
+	defaultParamsRefresh   = 10 * time.Minute
+	defaultVerifyTimeout   = 30 * time.Second
+	defaultAlertCooldown   = 5 * time.Minute
+	defaultAlertDedup      = 30 * time.Minute
+	defaultSwitchStorm     = 5 * time.Minute
+	defaultBalanceInterval = 1 * time.Minute
+	defaultFeeGrantCheck   = 10 * time.Minute
+	defaultExpiryWarning   = 72 * time.Hour
+	defaultChainProfile    = "wasm"
 	defaultTickEventType   = "ojo.oracle.v1.EventSetFxRate"
+
+	stampFormatStamps = "stamps"
 )
 
 var (
@@ -34,6 +49,26 @@ type (
 		RPC      RPC            `mapstructure:"rpc" validate:"required,gt=0,dive,required"`
 		Restart  RestartConfig  `mapstructure:"restart" validate:"required"`
 		FeeGrant FeeGrantConfig `mapstructure:"fee_grant" validate:"dive"`
+		Authz    AuthzConfig    `mapstructure:"authz"`
+		Signer   SignerConfig   `mapstructure:"signer"`
+		Quorum   QuorumConfig   `mapstructure:"quorum"`
+		Schedule ScheduleConfig `mapstructure:"schedule"`
+
+		Completeness CompletenessConfig `mapstructure:"completeness"`
+		Staleness    StalenessConfig    `mapstructure:"staleness"`
+		Outliers     OutlierConfig      `mapstructure:"outlier_filter"`
+
+		// twap and ema series relayed as additional symbols, the rate history is
+		// backfilled from ojo on startup if derived_series_warmup is set
+		DerivedSeries       []SeriesConfig `mapstructure:"derived_series" validate:"dive"`
+		DerivedSeriesWarmup bool           `mapstructure:"derived_series_warmup"`
+
+		// symbols relayed with the rate of an expression over the queried rates
+		SyntheticSymbols []SyntheticSymbol `mapstructure:"synthetic_symbols" validate:"dive"`
+
+		Verify  VerifyConfig  `mapstructure:"verify"`
+		Alerts  AlertConfig   `mapstructure:"alerts"`
+		Balance BalanceConfig `mapstructure:"balance"`
 
 		ProviderTimeout string `mapstructure:"provider_timeout"`
 		ContractAddress string `mapstructure:"contract_address"`
@@ -41,6 +76,7 @@ type (
 		EventTimeout    string `mapstructure:"event_timeout"`
 		MaxTickTimeout  string `mapstructure:"max_tick_timeout"`
 		QueryTimeout    string `mapstructure:"query_timeout"`
+		QueryBackoff    string `mapstructure:"query_backoff"`
 		MaxRetries      int64  `mapstructure:"max_retries"`
 
 		MedianRequestID    uint64 `mapstructure:"median_request_id"`
@@ -59,13 +95,72 @@ type (
 		// if true, would ignore any errors when querying median or deviations
 		IgnoreMedianErrors bool `mapstructure:"ignore_median_errors"`
 
+		// if true, exchange rates are decoded from the tick event attributes,
+		// exchange rates are queried from ojo if the events carry no rates
+		EventRates bool `mapstructure:"event_rates"`
+
+		// format of historical median and deviation values, "rates" relays the rates only,
+		// "stamps" relays each rate with the ojo block number and time it was stamped at.
+		// The price-feed contract of this repo only accepts rates, so "stamps" requires
+		// stamp_contract to confirm the contract accepts stamp objects
+		StampFormat   string `mapstructure:"stamp_format" validate:"omitempty,oneof=rates stamps"`
+		StampContract bool   `mapstructure:"stamp_contract"`
+
+		// number of most recent historical stamps relayed for each denom, overridden per denom
+		// by history_windows. All stamps returned by ojo are relayed if the window is 0.
+		// Denoms of history_windows are uppercased, as config keys are read in lowercase
+		HistoryWindow  int            `mapstructure:"history_window" validate:"gte=0"`
+		HistoryWindows map[string]int `mapstructure:"history_windows" validate:"dive,gte=0"`
+
 		GasAdjustment float64 `mapstructure:"gas_adjustment" validate:"required"`
 		GasPrices     string  `mapstructure:"gas_prices" validate:"required"`
 
-		// query rpc for ojo node
+		// query rpc for ojo node, fallback rpcs are only used when all query rpcs fail
 		QueryRPCS     []string `mapstructure:"query_rpcs" validate:"required"`
+		FallbackRPCS  []string `mapstructure:"fallback_query_rpcs"`
 		EventRPCS     []string `mapstructure:"event_rpcs" validate:"required"`
 		TickEventType string   `mapstructure:"event_type"`
+
+		// transport options of the query, event and tendermint rpc endpoints
+		Endpoints []EndpointConfig `mapstructure:"endpoints" validate:"dive"`
+
+		// event triggers and the relay profiles they are mapped to,
+		// event_type is used as the only trigger if none are set
+		EventTriggers []EventTrigger `mapstructure:"event_triggers" validate:"dive"`
+		RelayProfiles []RelayProfile `mapstructure:"relay_profiles" validate:"dive"`
+	}
+
+	// EndpointConfig defines the transport options of the rpc endpoint with the same address.
+	EndpointConfig struct {
+		Address  string            `mapstructure:"address" validate:"required"`
+		Source   string            `mapstructure:"source" validate:"omitempty,oneof=grpc lcd"`
+		CAFile   string            `mapstructure:"ca_file"`
+		CertFile string            `mapstructure:"cert_file" validate:"required_with=KeyFile"`
+		KeyFile  string            `mapstructure:"key_file" validate:"required_with=CertFile"`
+		Token    string            `mapstructure:"token"`
+		Headers  map[string]string `mapstructure:"headers"`
+	}
+
+	// EventTrigger defines an ojo end block event which triggers a relayer tick.
+	// The event must match all the attribute filters to trigger a tick.
+	EventTrigger struct {
+		EventType  string            `mapstructure:"event_type" validate:"required"`
+		Attributes []AttributeFilter `mapstructure:"attributes" validate:"dive"`
+		Profile    string            `mapstructure:"profile"`
+	}
+
+	// AttributeFilter matches an event attribute by key and, if values are set,
+	// by one of the given values.
+	AttributeFilter struct {
+		Key    string   `mapstructure:"key" validate:"required"`
+		Values []string `mapstructure:"values"`
+	}
+
+	// RelayProfile defines the denoms relayed when a trigger mapped to the profile fires.
+	// All denoms are relayed if none are set.
+	RelayProfile struct {
+		Name   string   `mapstructure:"name" validate:"required"`
+		Denoms []string `mapstructure:"denoms"`
 	}
 
 	// Account defines account related configuration that is related to the Client
@@ -74,6 +169,7 @@ type (
 		AccPrefix string `mapstructure:"acc_prefix" validate:"required"`
 		ChainID   string `mapstructure:"chain_id" validate:"required"`
 		Address   string `mapstructure:"address" validate:"required"`
+		Profile   string `mapstructure:"profile" validate:"omitempty,oneof=wasm ethermint"`
 	}
 
 	// Keyring defines the required Client-chain keyring configuration.
@@ -88,8 +184,141 @@ type (
 		SkipError bool   `mapstructure:"skip_error"`
 	}
 
+	// FeeGrantConfig defines the fee granter of relay txs. The allowance is checked on startup, every
+	// interval and when a tx is rejected by the allowance, and alerted expiry_warning before it expires
+	// or when its spend limit is below spend_warning. If fallback is set, txs are paid by the relayer
+	// while the allowance is missing or expired.
 	FeeGrantConfig struct {
-		Granter string `mapstructure:"granter" validate:"omitempty,required"`
+		Granter       string `mapstructure:"granter" validate:"omitempty,required"`
+		Interval      string `mapstructure:"interval"`
+		ExpiryWarning string `mapstructure:"expiry_warning"`
+		SpendWarning  string `mapstructure:"spend_warning"`
+		Fallback      bool   `mapstructure:"fallback"`
+	}
+
+	// AuthzConfig defines the granter account which contract messages are executed on behalf of,
+	// the relay txs are signed by the relayer key as grantee and wrapped in an authz MsgExec.
+	AuthzConfig struct {
+		Granter string `mapstructure:"granter"`
+	}
+
+	// SignerConfig defines the remote signer of relay txs, the keyring key of the relayer address is used
+	// if remote is not set. The transport options of remote addresses are set in endpoints.
+	// If multisig is set, the relayer address is a multisig signed by its members instead.
+	SignerConfig struct {
+		Remote   string          `mapstructure:"remote"`
+		Multisig *MultisigConfig `mapstructure:"multisig"`
+	}
+
+	// MultisigConfig defines the threshold and members of the multisig relayer account.
+	MultisigConfig struct {
+		Threshold int              `mapstructure:"threshold" validate:"required,gt=0"`
+		Members   []MultisigMember `mapstructure:"members" validate:"required,dive"`
+	}
+
+	// MultisigMember defines a multisig member key, held by the keyring or by the remote signer if set.
+	MultisigMember struct {
+		Address string `mapstructure:"address" validate:"required"`
+		Remote  string `mapstructure:"remote"`
+	}
+
+	// QuorumConfig defines the number of query rpcs which must return matching prices
+	// at the same height before relaying, and the max relative difference between prices.
+	QuorumConfig struct {
+		Size      int    `mapstructure:"size"`
+		Tolerance string `mapstructure:"tolerance"`
+	}
+
+	// ScheduleConfig defines time intervals between median and deviation relays, measured on the
+	// wasmd block time or the wall clock. median_duration and deviation_duration are used if unset.
+	// If from_params is set, the cadence is derived from the ojo oracle params instead,
+	// which are queried again every params_refresh.
+	ScheduleConfig struct {
+		Clock             string `mapstructure:"clock" validate:"omitempty,oneof=block wall"`
+		MedianInterval    string `mapstructure:"median_interval"`
+		DeviationInterval string `mapstructure:"deviation_interval"`
+		FromParams        bool   `mapstructure:"from_params"`
+		ParamsRefresh     string `mapstructure:"params_refresh"`
+	}
+
+	// CompletenessConfig defines the denoms expected in every relay, from expected_denoms and the ojo
+	// accept list if accept_list is set, and the policy applied when some of them are missing.
+	CompletenessConfig struct {
+		ExpectedDenoms []string `mapstructure:"expected_denoms"`
+		AcceptList     bool     `mapstructure:"accept_list"`
+		Policy         string   `mapstructure:"policy" validate:"omitempty,oneof=relay hold alert"`
+	}
+
+	// StalenessConfig defines when ojo prices are stale and are not relayed, after the rates have not
+	// changed for max_unchanged_ticks ticks, or if the queried ojo block is older than max_age.
+	StalenessConfig struct {
+		MaxUnchangedTicks int64  `mapstructure:"max_unchanged_ticks" validate:"gte=0"`
+		MaxAge            string `mapstructure:"max_age"`
+	}
+
+	// OutlierConfig defines the filter of exchange rates outside their latest ojo median plus or minus
+	// k times the median deviation, outliers are dropped, clamped to the band or flagged by action.
+	OutlierConfig struct {
+		K      string `mapstructure:"k"`
+		Action string `mapstructure:"action" validate:"omitempty,oneof=drop clamp flag"`
+	}
+
+	// SeriesConfig defines a twap or ema series of the denoms over the window, relayed as DENOM.SUFFIX.
+	// All relayed denoms are used if none are set, the suffix defaults to the kind and window, e.g. TWAP30M.
+	SeriesConfig struct {
+		Denoms []string `mapstructure:"denoms"`
+		Kind   string   `mapstructure:"kind" validate:"required,oneof=twap ema"`
+		Window string   `mapstructure:"window" validate:"required"`
+		Suffix string   `mapstructure:"suffix"`
+	}
+
+	// SyntheticSymbol defines a symbol relayed with the rate of the expression, e.g. "(ATOM + OJO) / 2".
+	SyntheticSymbol struct {
+		Symbol     string `mapstructure:"symbol" validate:"required"`
+		Expression string `mapstructure:"expression" validate:"required"`
+	}
+
+	// VerifyConfig enables the verification of relayed rates against the contract state,
+	// waiting up to timeout for the relay tx to be included.
+	VerifyConfig struct {
+		Enabled bool   `mapstructure:"enabled"`
+		Timeout string `mapstructure:"timeout"`
+	}
+
+	// AlertConfig defines the sinks of relayer alerts. An alert is not sent again within dedup_window,
+	// and alerts of the same incident are sent at most once per cooldown. An rpc switch storm is alerted
+	// after switch_storm_threshold ojo endpoint switches within switch_storm_window.
+	AlertConfig struct {
+		Sinks                []AlertSink `mapstructure:"sinks" validate:"dive"`
+		Cooldown             string      `mapstructure:"cooldown"`
+		DedupWindow          string      `mapstructure:"dedup_window"`
+		SwitchStormThreshold int         `mapstructure:"switch_storm_threshold" validate:"gte=0"`
+		SwitchStormWindow    string      `mapstructure:"switch_storm_window"`
+	}
+
+	// AlertSink defines a webhook receiving alerts as json, a slack webhook, or the log sink for local runs.
+	AlertSink struct {
+		Type string `mapstructure:"type" validate:"required,oneof=webhook slack log"`
+		URL  string `mapstructure:"url" validate:"required_unless=Type log"`
+	}
+
+	// BalanceConfig defines the monitoring of the relayer balance in denom, queried every interval and
+	// alerted below the warning and critical thresholds. Monitoring is disabled if denom is empty.
+	BalanceConfig struct {
+		Denom    string       `mapstructure:"denom"`
+		Interval string       `mapstructure:"interval"`
+		Warning  string       `mapstructure:"warning"`
+		Critical string       `mapstructure:"critical"`
+		TopUp    *TopUpConfig `mapstructure:"top_up"`
+	}
+
+	// TopUpConfig defines the top up of the relayer balance from the funder key in the relayer keyring,
+	// amount is sent when the balance is below floor, and at most daily_cap is sent within 24 hours.
+	TopUpConfig struct {
+		Funder   string `mapstructure:"funder" validate:"required"`
+		Floor    string `mapstructure:"floor" validate:"required"`
+		Amount   string `mapstructure:"amount" validate:"required"`
+		DailyCap string `mapstructure:"daily_cap" validate:"required"`
 	}
 
 	// RPC defines RPC configuration of both the wasmd chain and Tendermint nodes.
@@ -105,6 +334,50 @@ func (c Config) Validate() error {
 	return validate.Struct(c)
 }
 
+// UsesKeyring returns true if relay txs are signed by any keyring key.
+func (c SignerConfig) UsesKeyring() bool {
+	if c.Multisig == nil {
+		return len(c.Remote) == 0
+	}
+
+	for _, member := range c.Multisig.Members {
+		if len(member.Remote) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Endpoint returns the endpoint of the address with the transport options configured for it.
+func (c Config) Endpoint(address string) endpoint.Endpoint {
+	for _, e := range c.Endpoints {
+		if e.Address == address {
+			return endpoint.Endpoint{
+				Address:  e.Address,
+				Source:   e.Source,
+				CAFile:   e.CAFile,
+				CertFile: e.CertFile,
+				KeyFile:  e.KeyFile,
+				Token:    e.Token,
+				Headers:  e.Headers,
+			}
+		}
+	}
+
+	return endpoint.New(address)
+}
+
+// EndpointList returns the endpoints of the addresses with their transport options.
+func (c Config) EndpointList(addresses []string) []endpoint.Endpoint {
+	endpoints := make([]endpoint.Endpoint, len(addresses))
+	for i, address := range addresses {
+		endpoints[i] = c.Endpoint(address)
+	}
+
+	return endpoints
+}
+
 // ParseConfig attempts to read and parse configuration from the given file path.
 // An error is returned if reading or parsing the config fails.
 func ParseConfig(configPath string) (Config, error) {
@@ -125,6 +398,10 @@ func ParseConfig(configPath string) (Config, error) {
 		return cfg, fmt.Errorf("failed to decode config: %w", err)
 	}
 
+	if len(cfg.Account.Profile) == 0 {
+		cfg.Account.Profile = defaultChainProfile
+	}
+
 	if len(cfg.ProviderTimeout) == 0 {
 		cfg.ProviderTimeout = defaultProviderTimeout.String()
 	}
@@ -153,6 +430,48 @@ func ParseConfig(configPath string) (Config, error) {
 		cfg.QueryTimeout = defaultTimeout.String()
 	}
 
+	if len(cfg.QueryBackoff) == 0 {
+		cfg.QueryBackoff = defaultQueryBackoff.String()
+	}
+
+	if len(cfg.Schedule.ParamsRefresh) == 0 {
+		cfg.Schedule.ParamsRefresh = defaultParamsRefresh.String()
+	}
+
+	if len(cfg.Verify.Timeout) == 0 {
+		cfg.Verify.Timeout = defaultVerifyTimeout.String()
+	}
+
+	if len(cfg.Alerts.Cooldown) == 0 {
+		cfg.Alerts.Cooldown = defaultAlertCooldown.String()
+	}
+
+	if len(cfg.Alerts.DedupWindow) == 0 {
+		cfg.Alerts.DedupWindow = defaultAlertDedup.String()
+	}
+
+	if len(cfg.Alerts.SwitchStormWindow) == 0 {
+		cfg.Alerts.SwitchStormWindow = defaultSwitchStorm.String()
+	}
+
+	if len(cfg.FeeGrant.Interval) == 0 {
+		cfg.FeeGrant.Interval = defaultFeeGrantCheck.String()
+	}
+
+	if len(cfg.FeeGrant.ExpiryWarning) == 0 {
+		cfg.FeeGrant.ExpiryWarning = defaultExpiryWarning.String()
+	}
+
+	if len(cfg.Balance.Interval) == 0 {
+		cfg.Balance.Interval = defaultBalanceInterval.String()
+	}
+
+	for i, series := range cfg.DerivedSeries {
+		if len(series.Suffix) == 0 {
+			cfg.DerivedSeries[i].Suffix = strings.ToUpper(series.Kind + series.Window)
+		}
+	}
+
 	if len(cfg.ResolveDuration) == 0 {
 		cfg.ResolveDuration = defaultResolveDuration.String()
 	}
@@ -161,6 +480,63 @@ func ParseConfig(configPath string) (Config, error) {
 		cfg.TickEventType = defaultTickEventType
 	}
 
+	if len(cfg.EventTriggers) == 0 {
+		cfg.EventTriggers = []EventTrigger{{EventType: cfg.TickEventType}}
+	}
+
+	profiles := make(map[string]struct{}, len(cfg.RelayProfiles))
+	for _, profile := range cfg.RelayProfiles {
+		if _, found := profiles[profile.Name]; found {
+			return cfg, fmt.Errorf("duplicate relay profile %s", profile.Name)
+		}
+
+		profiles[profile.Name] = struct{}{}
+	}
+
+	for _, trigger := range cfg.EventTriggers {
+		if len(trigger.Profile) == 0 {
+			continue
+		}
+
+		if _, found := profiles[trigger.Profile]; !found {
+			return cfg, fmt.Errorf("relay profile %s not found for event %s", trigger.Profile, trigger.EventType)
+		}
+	}
+
+	if cfg.Signer.Multisig != nil && cfg.Signer.Multisig.Threshold > len(cfg.Signer.Multisig.Members) {
+		return cfg, fmt.Errorf(
+			"multisig threshold %d exceeds %d members",
+			cfg.Signer.Multisig.Threshold,
+			len(cfg.Signer.Multisig.Members),
+		)
+	}
+
+	if cfg.Quorum.Size > len(cfg.QueryRPCS) {
+		return cfg, fmt.Errorf("quorum size %d exceeds %d query rpcs", cfg.Quorum.Size, len(cfg.QueryRPCS))
+	}
+
+	// event rates are decoded from a single event rpc, so they cannot be checked by a quorum
+	if cfg.Quorum.Size > 1 && cfg.EventRates {
+		return cfg, fmt.Errorf("event_rates cannot be used with a quorum of size %d", cfg.Quorum.Size)
+	}
+
+	if cfg.StampFormat == stampFormatStamps && !cfg.StampContract {
+		return cfg, fmt.Errorf("stamp_format %q is rejected by the price-feed contract, set stamp_contract if the contract accepts stamps", cfg.StampFormat)
+	}
+
+	// ojo denoms are uppercase, config keys are read in lowercase
+	historyWindows := make(map[string]int, len(cfg.HistoryWindows))
+	for denom, size := range cfg.HistoryWindows {
+		historyWindows[strings.ToUpper(denom)] = size
+	}
+	cfg.HistoryWindows = historyWindows
+
+	for _, e := range cfg.Endpoints {
+		if err := cfg.Endpoint(e.Address).Validate(); err != nil {
+			return cfg, err
+		}
+	}
+
 	if cfg.MaxRetries == 0 {
 		cfg.MaxRetries = defaultRetries
 	}
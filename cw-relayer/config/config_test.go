@@ -80,3 +80,290 @@ rpc_timeout = "100ms"
 	_, err = config.ParseConfig(tmpFile.Name())
 	require.NoError(t, err)
 }
+
+func TestParseConfig_EventTriggers(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "cw-relayer*.toml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	content := []byte(`
+gas_adjustment = 1.5
+contract_address = "wasm14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s0phg4d"
+gas_prices = "0.00025stake"
+query_rpcs = ["http://localhost:26657"]
+event_rpcs = ["http://localhost:26657"]
+
+[[event_triggers]]
+event_type = "ojo.oracle.v1.EventSetFxRate"
+profile = "crypto"
+[[event_triggers.attributes]]
+key = "denom"
+values = ["ATOM", "OSMO"]
+
+[[relay_profiles]]
+name = "crypto"
+denoms = ["ATOM", "OSMO"]
+
+[account]
+address = "wasm15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+chain_id = "wasm-local-testnet"
+acc_prefix = "wasm"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.wasm"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+query_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`)
+	_, err = tmpFile.Write(content)
+	require.NoError(t, err)
+
+	cfg, err := config.ParseConfig(tmpFile.Name())
+	require.NoError(t, err)
+	require.Len(t, cfg.EventTriggers, 1)
+	require.Equal(t, "crypto", cfg.EventTriggers[0].Profile)
+	require.Equal(t, []string{"ATOM", "OSMO"}, cfg.EventTriggers[0].Attributes[0].Values)
+	require.Len(t, cfg.RelayProfiles, 1)
+}
+
+func TestParseConfig_EndpointToken(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "cw-relayer*.toml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	content := []byte(`
+gas_adjustment = 1.5
+contract_address = "wasm14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s0phg4d"
+gas_prices = "0.00025stake"
+query_rpcs = ["http://localhost:9090"]
+event_rpcs = ["http://localhost:26657"]
+
+[[endpoints]]
+address = "http://localhost:9090"
+token = "secret"
+
+[account]
+address = "wasm15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+chain_id = "wasm-local-testnet"
+acc_prefix = "wasm"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.wasm"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+query_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`)
+	_, err = tmpFile.Write(content)
+	require.NoError(t, err)
+
+	// bearer tokens are not sent over plaintext tcp
+	_, err = config.ParseConfig(tmpFile.Name())
+	require.ErrorContains(t, err, "requires a TLS or unix:// address")
+}
+
+func TestParseConfig_HistoryWindows(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "cw-relayer*.toml")
+	require.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	content := []byte(`
+gas_adjustment = 1.5
+contract_address = "wasm14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s0phg4d"
+gas_prices = "0.00025stake"
+query_rpcs = ["http://localhost:26657"]
+event_rpcs = ["http://localhost:26657"]
+history_window = 5
+
+[history_windows]
+ATOM = 3
+Osmo = 0
+
+[account]
+address = "wasm15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+chain_id = "wasm-local-testnet"
+acc_prefix = "wasm"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.wasm"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+query_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`)
+	_, err = tmpFile.Write(content)
+	require.NoError(t, err)
+
+	// denoms match the uppercase ojo denoms whatever the case of the config keys
+	cfg, err := config.ParseConfig(tmpFile.Name())
+	require.NoError(t, err)
+	require.Equal(t, 5, cfg.HistoryWindow)
+	require.Equal(t, map[string]int{"ATOM": 3, "OSMO": 0}, cfg.HistoryWindows)
+}
+
+func TestParseConfig_TopUpDailyCap(t *testing.T) {
+	base := `
+gas_adjustment = 1.5
+contract_address = "wasm14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s0phg4d"
+gas_prices = "0.00025stake"
+query_rpcs = ["http://localhost:26657"]
+event_rpcs = ["http://localhost:26657"]
+
+[account]
+address = "wasm15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+chain_id = "wasm-local-testnet"
+acc_prefix = "wasm"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.wasm"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+query_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+
+[balance]
+denom = "stake"
+
+[balance.top_up]
+funder = "wasm15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+floor = "5000000"
+amount = "10000000"
+`
+
+	for _, tc := range []struct {
+		name      string
+		content   string
+		expectErr bool
+	}{
+		{name: "missing daily cap", content: base, expectErr: true},
+		{name: "daily cap", content: base + `daily_cap = "50000000"` + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp("", "cw-relayer*.toml")
+			require.NoError(t, err)
+			defer os.Remove(tmpFile.Name())
+
+			_, err = tmpFile.Write([]byte(tc.content))
+			require.NoError(t, err)
+
+			_, err = config.ParseConfig(tmpFile.Name())
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParseConfig_StampFormat(t *testing.T) {
+	base := `
+gas_adjustment = 1.5
+contract_address = "wasm14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s0phg4d"
+gas_prices = "0.00025stake"
+query_rpcs = ["http://localhost:26657"]
+event_rpcs = ["http://localhost:26657"]
+
+[account]
+address = "wasm15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+chain_id = "wasm-local-testnet"
+acc_prefix = "wasm"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.wasm"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+query_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	for _, tc := range []struct {
+		name      string
+		content   string
+		expectErr bool
+	}{
+		{name: "rates", content: `stamp_format = "rates"` + "\n" + base},
+		{name: "stamps for the price-feed contract", content: `stamp_format = "stamps"` + "\n" + base, expectErr: true},
+		{name: "stamps for a stamp contract", content: `stamp_format = "stamps"` + "\nstamp_contract = true\n" + base},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp("", "cw-relayer*.toml")
+			require.NoError(t, err)
+			defer os.Remove(tmpFile.Name())
+
+			_, err = tmpFile.Write([]byte(tc.content))
+			require.NoError(t, err)
+
+			_, err = config.ParseConfig(tmpFile.Name())
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParseConfig_QuorumEventRates(t *testing.T) {
+	base := `
+gas_adjustment = 1.5
+contract_address = "wasm14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9s0phg4d"
+gas_prices = "0.00025stake"
+query_rpcs = ["http://localhost:26657", "http://localhost:26658"]
+event_rpcs = ["http://localhost:26657"]
+
+[account]
+address = "wasm15nejfgcaanqpw25ru4arvfd0fwy6j8clccvwx4"
+chain_id = "wasm-local-testnet"
+acc_prefix = "wasm"
+
+[keyring]
+backend = "test"
+dir = "/Users/username/.wasm"
+
+[rpc]
+tmrpc_endpoint = "http://localhost:26657"
+query_endpoint = "localhost:9090"
+rpc_timeout = "100ms"
+`
+
+	for _, tc := range []struct {
+		name      string
+		content   string
+		expectErr bool
+	}{
+		{name: "event rates", content: "event_rates = true\n" + base},
+		{name: "quorum", content: base + "\n[quorum]\nsize = 2\n"},
+		{name: "event rates with quorum", content: "event_rates = true\n" + base + "\n[quorum]\nsize = 2\n", expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp("", "cw-relayer*.toml")
+			require.NoError(t, err)
+			defer os.Remove(tmpFile.Name())
+
+			_, err = tmpFile.Write([]byte(tc.content))
+			require.NoError(t, err)
+
+			_, err = config.ParseConfig(tmpFile.Name())
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package alert
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/rs/zerolog"
+)
+
+// Alert severities.
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+
+	sendTimeout = 10 * time.Second
+)
+
+type (
+	// Alert defines a relayer incident. Alerts with the same name belong to the same incident.
+	Alert struct {
+		Name     string            `json:"name"`
+		Severity string            `json:"severity"`
+		Message  string            `json:"message"`
+		Fields   map[string]string `json:"fields,omitempty"`
+		Time     time.Time         `json:"time"`
+	}
+
+	// Sink delivers alerts to an external system.
+	Sink interface {
+		Send(ctx context.Context, alert Alert) error
+	}
+
+	// Alerter fires alerts to its sinks. An alert is not sent again while the same alert, with the
+	// same message and fields, was sent within the dedup window, and alerts with the same name are sent at most once per cooldown.
+	// A nil Alerter drops all alerts.
+	Alerter struct {
+		logger   zerolog.Logger
+		sinks    []Sink
+		cooldown time.Duration
+		dedup    time.Duration
+
+		mtx      sync.Mutex
+		lastName map[string]time.Time
+		lastSent map[string]time.Time
+	}
+)
+
+// NewAlerter returns an alerter sending alerts to the sinks.
+func NewAlerter(logger zerolog.Logger, sinks []Sink, cooldown, dedup time.Duration) *Alerter {
+	return &Alerter{
+		logger:   logger.With().Str("module", "alert").Logger(),
+		sinks:    sinks,
+		cooldown: cooldown,
+		dedup:    dedup,
+		lastName: map[string]time.Time{},
+		lastSent: map[string]time.Time{},
+	}
+}
+
+// Fire sends the alert to all sinks in the background, unless it is suppressed by
+// the dedup or cooldown window. It returns true if the alert is sent.
+func (a *Alerter) Fire(alert Alert) bool {
+	if a == nil {
+		return false
+	}
+
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+
+	if !a.allow(alert) {
+		a.logger.Debug().Str("alert", alert.Name).Msg("alert suppressed")
+		return false
+	}
+
+	telemetry.IncrCounter(1, "alert", alert.Name)
+	for _, sink := range a.sinks {
+		go func(sink Sink) {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+
+			if err := sink.Send(ctx, alert); err != nil {
+				telemetry.IncrCounter(1, "failure", "alert")
+				a.logger.Err(err).Str("alert", alert.Name).Msg("error sending alert")
+			}
+		}(sink)
+	}
+
+	return true
+}
+
+// Resolve clears the cooldown and dedup windows of the alert name,
+// so that the next incident is alerted immediately.
+func (a *Alerter) Resolve(name string) {
+	if a == nil {
+		return
+	}
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	delete(a.lastName, name)
+	for key := range a.lastSent {
+		if strings.HasPrefix(key, name+"/") {
+			delete(a.lastSent, key)
+		}
+	}
+}
+
+// allow returns true and records the alert if it is outside the dedup and cooldown windows.
+func (a *Alerter) allow(alert Alert) bool {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	key := dedupKey(alert)
+	if last, found := a.lastSent[key]; found && alert.Time.Sub(last) < a.dedup {
+		return false
+	}
+
+	if last, found := a.lastName[alert.Name]; found && alert.Time.Sub(last) < a.cooldown {
+		return false
+	}
+
+	a.lastSent[key] = alert.Time
+	a.lastName[alert.Name] = alert.Time
+	return true
+}
+
+// dedupKey identifies an alert by its name, message and fields sorted by key.
+func dedupKey(alert Alert) string {
+	keys := make([]string, 0, len(alert.Fields))
+	for key := range alert.Fields {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(alert.Name + "/" + alert.Message)
+	for _, key := range keys {
+		b.WriteString("/" + key + "=" + alert.Fields[key])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlerter_Fire(t *testing.T) {
+	sink := NewLogSink(zerolog.Nop())
+	alerter := NewAlerter(zerolog.Nop(), []Sink{sink}, time.Minute, time.Hour)
+	start := time.Unix(0, 0)
+
+	require.True(t, alerter.Fire(Alert{Name: "stale", Message: "a", Time: start}))
+
+	// same alert within the dedup window
+	require.False(t, alerter.Fire(Alert{Name: "stale", Message: "a", Time: start.Add(2 * time.Minute)}))
+
+	// different alert of the same name within the cooldown
+	require.False(t, alerter.Fire(Alert{Name: "stale", Message: "b", Time: start.Add(30 * time.Second)}))
+	require.True(t, alerter.Fire(Alert{Name: "stale", Message: "b", Time: start.Add(2 * time.Minute)}))
+
+	// alerts with other fields are not deduplicated
+	fields := map[string]string{"denom": "ATOM"}
+	require.True(t, alerter.Fire(Alert{Name: "outlier", Message: "a", Fields: fields, Time: start}))
+	require.True(t, alerter.Fire(Alert{Name: "outlier", Message: "a", Fields: map[string]string{"denom": "OSMO"}, Time: start.Add(2 * time.Minute)}))
+	require.False(t, alerter.Fire(Alert{Name: "outlier", Message: "a", Fields: fields, Time: start.Add(4 * time.Minute)}))
+
+	// other names are not suppressed
+	require.True(t, alerter.Fire(Alert{Name: "missed", Message: "a", Time: start.Add(2 * time.Minute)}))
+
+	// resolved alerts fire again
+	alerter.Resolve("stale")
+	require.True(t, alerter.Fire(Alert{Name: "stale", Message: "a", Time: start.Add(3 * time.Minute)}))
+
+	require.Eventually(t, func() bool { return len(sink.Alerts()) == 6 }, time.Second, 10*time.Millisecond)
+
+	var nilAlerter *Alerter
+	require.False(t, nilAlerter.Fire(Alert{Name: "stale"}))
+}
+
+func TestWebhookSink_Send(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	alert := Alert{Name: "stale", Severity: SeverityCritical, Message: "ojo prices are stale", Fields: map[string]string{"height": "10"}}
+
+	require.NoError(t, NewWebhookSink(server.URL, false).Send(context.Background(), alert))
+	require.Equal(t, "stale", received["name"])
+
+	require.NoError(t, NewWebhookSink(server.URL, true).Send(context.Background(), alert))
+	require.Equal(t, "*[CRITICAL] stale*: ojo prices are stale\n• height: `10`", received["text"])
+}
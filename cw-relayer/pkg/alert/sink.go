@@ -0,0 +1,140 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Supported sink types.
+const (
+	SinkWebhook = "webhook"
+	SinkSlack   = "slack"
+	SinkLog     = "log"
+)
+
+type (
+	// WebhookSink posts alerts as JSON to a webhook, either as the generic alert object
+	// or as a Slack-compatible message payload.
+	WebhookSink struct {
+		url    string
+		slack  bool
+		client *http.Client
+	}
+
+	// LogSink logs alerts and keeps them in memory, it is meant for local runs and tests.
+	LogSink struct {
+		logger zerolog.Logger
+		mtx    sync.Mutex
+		alerts []Alert
+	}
+
+	slackMessage struct {
+		Text string `json:"text"`
+	}
+)
+
+// NewSink returns the sink of the given type.
+func NewSink(logger zerolog.Logger, sinkType, url string) (Sink, error) {
+	switch sinkType {
+	case SinkWebhook, SinkSlack:
+		if len(url) == 0 {
+			return nil, fmt.Errorf("%s sink requires a url", sinkType)
+		}
+
+		return NewWebhookSink(url, sinkType == SinkSlack), nil
+	case SinkLog:
+		return NewLogSink(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown alert sink %s", sinkType)
+	}
+}
+
+// NewWebhookSink returns a sink posting alerts to the url, formatted as Slack messages if slack is set.
+func NewWebhookSink(url string, slack bool) *WebhookSink {
+	return &WebhookSink{url: url, slack: slack, client: &http.Client{}}
+}
+
+func (s *WebhookSink) Send(ctx context.Context, alert Alert) error {
+	var payload interface{} = alert
+	if s.slack {
+		payload = slackMessage{Text: slackText(alert)}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook failed with status %d: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+// NewLogSink returns a sink logging alerts.
+func NewLogSink(logger zerolog.Logger) *LogSink {
+	return &LogSink{logger: logger.With().Str("module", "alert_sink").Logger()}
+}
+
+func (s *LogSink) Send(_ context.Context, alert Alert) error {
+	s.mtx.Lock()
+	s.alerts = append(s.alerts, alert)
+	s.mtx.Unlock()
+
+	logs := s.logger.Warn().Str("alert", alert.Name).Str("severity", alert.Severity)
+	for key, value := range alert.Fields {
+		logs.Str(key, value)
+	}
+
+	logs.Msg(alert.Message)
+	return nil
+}
+
+// Alerts returns the alerts sent to the sink.
+func (s *LogSink) Alerts() []Alert {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return append([]Alert{}, s.alerts...)
+}
+
+// slackText formats the alert as a Slack message with the fields in key order.
+func slackText(alert Alert) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*[%s] %s*: %s", strings.ToUpper(alert.Severity), alert.Name, alert.Message)
+
+	keys := make([]string, 0, len(alert.Fields))
+	for key := range alert.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(&text, "\n• %s: `%s`", key, alert.Fields[key])
+	}
+
+	return text.String()
+}
@@ -0,0 +1,282 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	tmjsonclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ojo-network/cw-relayer/tools"
+)
+
+// Supported endpoint schemes, addresses without a scheme use plaintext tcp.
+const (
+	SchemeGRPC  = "grpc"
+	SchemeGRPCS = "grpcs"
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+	SchemeTCP   = "tcp"
+	SchemeUnix  = "unix"
+
+	headerAuthorization = "authorization"
+)
+
+// Endpoint defines the address and transport options of a grpc query or tendermint rpc endpoint.
+// TLS is used for grpcs:// and https:// addresses, verified against the system roots or the
+// CA bundle in CAFile, and a client certificate is presented if CertFile and KeyFile are set.
+// Headers and the bearer Token are sent with every request, the token is only sent over TLS
+// or unix sockets. Source selects the api used to query ojo prices from a query endpoint.
+type Endpoint struct {
+	Address  string
+	Source   string
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	Token    string
+	Headers  map[string]string
+}
+
+// New returns an endpoint for the address without any transport options.
+func New(address string) Endpoint {
+	return Endpoint{Address: address}
+}
+
+// String returns the endpoint address.
+func (e Endpoint) String() string {
+	return e.Address
+}
+
+// Scheme returns the address scheme, or an empty string if the address has none.
+func (e Endpoint) Scheme() string {
+	parts := strings.SplitN(e.Address, "://", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+
+	return strings.ToLower(parts[0])
+}
+
+// Secure returns true if the endpoint uses TLS.
+func (e Endpoint) Secure() bool {
+	switch e.Scheme() {
+	case SchemeGRPCS, SchemeHTTPS:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate returns an error if the bearer token would be sent over a plaintext tcp connection.
+func (e Endpoint) Validate() error {
+	if len(e.Token) > 0 && !e.Secure() && e.Scheme() != SchemeUnix {
+		return fmt.Errorf("bearer token of endpoint %s requires a TLS or unix:// address", e.Address)
+	}
+
+	return nil
+}
+
+// TLSConfig returns the TLS configuration of the endpoint.
+func (e Endpoint) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(e.CAFile) > 0 {
+		ca, err := os.ReadFile(e.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", e.CAFile)
+		}
+
+		config.RootCAs = pool
+	}
+
+	if len(e.CertFile) > 0 || len(e.KeyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(e.CertFile, e.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// Dial returns a grpc connection to the endpoint. The connection is established lazily.
+func (e Endpoint) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	var target string
+	switch e.Scheme() {
+	case SchemeGRPCS, SchemeHTTPS:
+		config, err := e.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		target = strings.SplitN(e.Address, "://", 2)[1]
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+
+	case SchemeGRPC, SchemeHTTP:
+		target = "passthrough:///" + strings.SplitN(e.Address, "://", 2)[1]
+		opts = append(
+			opts,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(tools.DialerFunc),
+		)
+
+	default:
+		// tcp:// and unix:// addresses are passed to the dialer as is
+		target = "passthrough:///" + e.Address
+		opts = append(
+			opts,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(tools.DialerFunc),
+		)
+	}
+
+	if len(e.Token) > 0 || len(e.Headers) > 0 {
+		opts = append(opts, grpc.WithPerRPCCredentials(headerCredentials{
+			headers:    e.headers(),
+			requireTLS: len(e.Token) > 0 && e.Scheme() != SchemeUnix,
+		}))
+	}
+
+	return grpc.Dial(target, opts...)
+}
+
+// HTTPClient returns an http client for the tendermint rpc of the endpoint,
+// supporting http://, https://, tcp:// and unix:// addresses.
+func (e Endpoint) HTTPClient(timeout time.Duration) (*http.Client, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	client, err := tmjsonclient.DefaultHTTPClient(e.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Timeout = timeout
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected http transport %T", client.Transport)
+	}
+
+	if e.Secure() {
+		config, err := e.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = config
+	}
+
+	if len(e.Token) > 0 || len(e.Headers) > 0 {
+		client.Transport = headerTransport{base: transport, headers: e.headers()}
+	}
+
+	return client, nil
+}
+
+// DialWebsocket opens a websocket connection to the path of the tendermint rpc of the endpoint,
+// with the TLS configuration and headers of the endpoint.
+func (e Endpoint) DialWebsocket(ctx context.Context, path string, timeout time.Duration) (*websocket.Conn, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	protocol, address := tools.ProtocolAndAddress(e.Address)
+	dialer := &websocket.Dialer{HandshakeTimeout: timeout, Proxy: http.ProxyFromEnvironment}
+
+	url := "ws://" + address + path
+	switch {
+	case e.Secure():
+		config, err := e.TLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		url = "wss://" + address + path
+		dialer.TLSClientConfig = config
+
+	case protocol == SchemeUnix:
+		// the host of the url is only used for the host header
+		url = "ws://localhost" + path
+		dialer.Proxy = nil
+		dialer.NetDialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, SchemeUnix, address)
+		}
+	}
+
+	header := http.Header{}
+	for key, value := range e.headers() {
+		header.Set(key, value)
+	}
+
+	conn, _, err := dialer.DialContext(ctx, url, header) //nolint:bodyclose
+	return conn, err
+}
+
+// headers returns the request headers of the endpoint, including the bearer token.
+func (e Endpoint) headers() map[string]string {
+	headers := make(map[string]string, len(e.Headers)+1)
+	for key, value := range e.Headers {
+		headers[strings.ToLower(key)] = value
+	}
+
+	if len(e.Token) > 0 {
+		headers[headerAuthorization] = "Bearer " + e.Token
+	}
+
+	return headers
+}
+
+// headerCredentials attaches the endpoint headers to every grpc request.
+type headerCredentials struct {
+	headers    map[string]string
+	requireTLS bool
+}
+
+func (c headerCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return c.headers, nil
+}
+
+// RequireTransportSecurity returns true if the headers carry a bearer token, unless the endpoint
+// is a unix socket. Plain headers can be used with plaintext connections, e.g. to TLS terminating gateways.
+func (c headerCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
+
+// headerTransport attaches the endpoint headers to every http request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,297 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+const testTimeout = 5 * time.Second
+
+func TestEndpoint_Validate(t *testing.T) {
+	testCases := []struct {
+		address   string
+		token     string
+		expectErr bool
+	}{
+		{address: "tcp://localhost:9090", token: "token", expectErr: true},
+		{address: "http://localhost:9090", token: "token", expectErr: true},
+		{address: "grpc://localhost:9090", token: "token", expectErr: true},
+		{address: "localhost:9090", token: "token", expectErr: true},
+		{address: "unix:///tmp/ojo.sock", token: "token"},
+		{address: "https://localhost:9090", token: "token"},
+		{address: "grpcs://localhost:9090", token: "token"},
+		{address: "tcp://localhost:9090"},
+	}
+
+	for _, tc := range testCases {
+		e := Endpoint{Address: tc.address, Token: tc.token}
+		if tc.expectErr {
+			require.Error(t, e.Validate(), tc.address)
+
+			_, err := e.Dial()
+			require.Error(t, err, tc.address)
+
+			_, err = e.HTTPClient(testTimeout)
+			require.Error(t, err, tc.address)
+			continue
+		}
+
+		require.NoError(t, e.Validate(), tc.address)
+	}
+}
+
+func TestEndpoint_HTTPClientTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(headerHandler())
+	defer srv.Close()
+
+	address := "https://" + srv.Listener.Addr().String()
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+
+	// the server certificate is not trusted by the system roots
+	client, err := Endpoint{Address: address}.HTTPClient(testTimeout)
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	require.Error(t, err)
+
+	client, err = Endpoint{
+		Address: address,
+		CAFile:  caFile,
+		Token:   "token",
+		Headers: map[string]string{"X-Api-Key": "key"},
+	}.HTTPClient(testTimeout)
+	require.NoError(t, err)
+	requireHeaders(t, client, srv.URL, "Bearer token", "key")
+}
+
+func TestEndpoint_HTTPClientMTLS(t *testing.T) {
+	certFile, keyFile, cert := newClientCert(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	srv := httptest.NewUnstartedServer(headerHandler())
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	e := Endpoint{
+		Address: "https://" + srv.Listener.Addr().String(),
+		CAFile:  writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw),
+	}
+
+	// the server requires a client certificate
+	client, err := e.HTTPClient(testTimeout)
+	require.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	require.Error(t, err)
+
+	e.CertFile = certFile
+	e.KeyFile = keyFile
+	client, err = e.HTTPClient(testTimeout)
+	require.NoError(t, err)
+	requireHeaders(t, client, srv.URL, "", "")
+}
+
+func TestEndpoint_HTTPClientUnix(t *testing.T) {
+	socket := listenUnix(t)
+
+	srv := httptest.NewUnstartedServer(headerHandler())
+	srv.Listener = socket
+	srv.Start()
+	defer srv.Close()
+
+	// tokens are sent over unix sockets without TLS
+	client, err := Endpoint{
+		Address: "unix://" + socket.Addr().String(),
+		Token:   "token",
+		Headers: map[string]string{"X-Api-Key": "key"},
+	}.HTTPClient(testTimeout)
+	require.NoError(t, err)
+	requireHeaders(t, client, "http://localhost/", "Bearer token", "key")
+}
+
+func TestEndpoint_DialUnix(t *testing.T) {
+	socket := listenUnix(t)
+
+	received := make(chan metadata.MD, 1)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		received <- md
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(socket) //nolint:errcheck
+	defer srv.Stop()
+
+	conn, err := Endpoint{
+		Address: "unix://" + socket.Addr().String(),
+		Token:   "token",
+		Headers: map[string]string{"X-Api-Key": "key"},
+	}.Dial()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	md := <-received
+	require.Equal(t, []string{"Bearer token"}, md.Get("authorization"))
+	require.Equal(t, []string{"key"}, md.Get("x-api-key"))
+}
+
+func TestEndpoint_DialWebsocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	headers := make(chan http.Header, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		conn.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	t.Run("tls", func(t *testing.T) {
+		srv := httptest.NewTLSServer(handler)
+		defer srv.Close()
+
+		address := "https://" + srv.Listener.Addr().String()
+
+		// the server certificate is not trusted by the system roots
+		_, err := Endpoint{Address: address}.DialWebsocket(ctx, "/websocket", testTimeout)
+		require.Error(t, err)
+
+		conn, err := Endpoint{
+			Address: address,
+			CAFile:  writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw),
+			Token:   "token",
+			Headers: map[string]string{"X-Api-Key": "key"},
+		}.DialWebsocket(ctx, "/websocket", testTimeout)
+		require.NoError(t, err)
+		conn.Close()
+
+		header := <-headers
+		require.Equal(t, "Bearer token", header.Get("Authorization"))
+		require.Equal(t, "key", header.Get("X-Api-Key"))
+	})
+
+	t.Run("unix", func(t *testing.T) {
+		socket := listenUnix(t)
+
+		srv := httptest.NewUnstartedServer(handler)
+		srv.Listener = socket
+		srv.Start()
+		defer srv.Close()
+
+		conn, err := Endpoint{
+			Address: "unix://" + socket.Addr().String(),
+			Token:   "token",
+		}.DialWebsocket(ctx, "/websocket", testTimeout)
+		require.NoError(t, err)
+		conn.Close()
+
+		require.Equal(t, "Bearer token", (<-headers).Get("Authorization"))
+	})
+
+	t.Run("plaintext token", func(t *testing.T) {
+		_, err := Endpoint{Address: "tcp://localhost:26657", Token: "token"}.DialWebsocket(ctx, "/websocket", testTimeout)
+		require.Error(t, err)
+	})
+}
+
+// headerHandler responds with the authorization and api key headers of the request.
+func headerHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization") + "\n" + r.Header.Get("X-Api-Key"))) //nolint:errcheck
+	})
+}
+
+func requireHeaders(t *testing.T, client *http.Client, url, authorization, apiKey string) {
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, authorization+"\n"+apiKey, string(body))
+}
+
+func listenUnix(t *testing.T) net.Listener {
+	dir, err := os.MkdirTemp("", "endpoint")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket, err := net.Listen("unix", filepath.Join(dir, "rpc.sock"))
+	require.NoError(t, err)
+
+	return socket
+}
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+
+	return path
+}
+
+// newClientCert writes a self signed client certificate and its key.
+func newClientCert(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "cw-relayer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return writePEM(t, "client.pem", "CERTIFICATE", der), writePEM(t, "client-key.pem", "EC PRIVATE KEY", keyDER), cert
+}
@@ -0,0 +1,69 @@
+package ethermint
+
+import (
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+var (
+	_ authtypes.AccountI                 = &EthAccount{}
+	_ codectypes.UnpackInterfacesMessage = &EthAccount{}
+)
+
+// EthAccount is the account type of ethermint chains, a base account with the code hash of the
+// EVM contract of the account. It is compatible with the ethermint account of the same name.
+type EthAccount struct {
+	BaseAccount *authtypes.BaseAccount
+	CodeHash    string
+}
+
+// GetAddress returns the account address.
+func (acc *EthAccount) GetAddress() sdk.AccAddress {
+	return acc.BaseAccount.GetAddress()
+}
+
+// SetAddress sets the account address.
+func (acc *EthAccount) SetAddress(address sdk.AccAddress) error {
+	return acc.BaseAccount.SetAddress(address)
+}
+
+// GetPubKey returns the account public key.
+func (acc *EthAccount) GetPubKey() cryptotypes.PubKey {
+	return acc.BaseAccount.GetPubKey()
+}
+
+// SetPubKey sets the account public key.
+func (acc *EthAccount) SetPubKey(pubKey cryptotypes.PubKey) error {
+	return acc.BaseAccount.SetPubKey(pubKey)
+}
+
+// GetAccountNumber returns the account number.
+func (acc *EthAccount) GetAccountNumber() uint64 {
+	return acc.BaseAccount.GetAccountNumber()
+}
+
+// SetAccountNumber sets the account number.
+func (acc *EthAccount) SetAccountNumber(accNumber uint64) error {
+	return acc.BaseAccount.SetAccountNumber(accNumber)
+}
+
+// GetSequence returns the account sequence.
+func (acc *EthAccount) GetSequence() uint64 {
+	return acc.BaseAccount.GetSequence()
+}
+
+// SetSequence sets the account sequence.
+func (acc *EthAccount) SetSequence(seq uint64) error {
+	return acc.BaseAccount.SetSequence(seq)
+}
+
+// UnpackInterfaces unpacks the public key of the base account.
+func (acc *EthAccount) UnpackInterfaces(unpacker codectypes.AnyUnpacker) error {
+	if acc.BaseAccount == nil {
+		return nil
+	}
+
+	return acc.BaseAccount.UnpackInterfaces(unpacker)
+}
@@ -0,0 +1,247 @@
+package ethermint
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The types are encoded by hand, as the ethermint module is not a dependency of the relayer.
+// The proto names and field numbers match the ethermint proto definitions.
+const (
+	privKeyProtoName    = "ethermint.crypto.v1.ethsecp256k1.PrivKey"
+	pubKeyProtoName     = "ethermint.crypto.v1.ethsecp256k1.PubKey"
+	ethAccountProtoName = "ethermint.types.v1.EthAccount"
+)
+
+// RegisterInterfaces registers the ethsecp256k1 keys and the ethermint account in the registry of the
+// ethermint profile. The proto names are returned by the types themselves and are not registered in the
+// global proto registry, so they do not conflict with the ethermint module of an embedding program.
+func RegisterInterfaces(registry codectypes.InterfaceRegistry) {
+	registry.RegisterImplementations((*cryptotypes.PrivKey)(nil), &PrivKey{})
+	registry.RegisterImplementations((*cryptotypes.PubKey)(nil), &PubKey{})
+	registry.RegisterImplementations((*authtypes.AccountI)(nil), &EthAccount{})
+}
+
+// RegisterLegacyAminoCodec registers the ethsecp256k1 keys.
+func RegisterLegacyAminoCodec(cdc *codec.LegacyAmino) {
+	cdc.RegisterConcrete(&PrivKey{}, PrivKeyName, nil)
+	cdc.RegisterConcrete(&PubKey{}, PubKeyName, nil)
+}
+
+// Reset implements proto.Message.
+func (privKey *PrivKey) Reset() { *privKey = PrivKey{} }
+
+// String implements proto.Message, the key bytes are not printed.
+func (*PrivKey) String() string { return "PrivKeyEthSecp256k1{...}" }
+
+// ProtoMessage implements proto.Message.
+func (*PrivKey) ProtoMessage() {}
+
+// XXX_MessageName returns the proto name of the key, whether or not the name is registered.
+func (*PrivKey) XXX_MessageName() string { return privKeyProtoName }
+
+// Marshal returns the proto encoding of the key.
+func (privKey *PrivKey) Marshal() ([]byte, error) {
+	return appendBytes(nil, 1, privKey.Key), nil
+}
+
+// MarshalTo writes the proto encoding of the key to the buffer.
+func (privKey *PrivKey) MarshalTo(dAtA []byte) (int, error) {
+	return copy(dAtA, appendBytes(nil, 1, privKey.Key)), nil
+}
+
+// MarshalToSizedBuffer writes the proto encoding of the key to the end of the buffer.
+func (privKey *PrivKey) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	return marshalToSizedBuffer(dAtA, appendBytes(nil, 1, privKey.Key)), nil
+}
+
+// Size returns the size of the proto encoding of the key.
+func (privKey *PrivKey) Size() int {
+	return sizeBytes(1, privKey.Key)
+}
+
+// Unmarshal decodes the proto encoding of the key.
+func (privKey *PrivKey) Unmarshal(dAtA []byte) error {
+	return unmarshalFields(dAtA, func(num protowire.Number, value []byte) error {
+		if num == 1 {
+			privKey.Key = append([]byte{}, value...)
+		}
+
+		return nil
+	})
+}
+
+// Reset implements proto.Message.
+func (pubKey *PubKey) Reset() { *pubKey = PubKey{} }
+
+// String implements proto.Message.
+func (pubKey *PubKey) String() string { return fmt.Sprintf("PubKeyEthSecp256k1{%X}", pubKey.Key) }
+
+// ProtoMessage implements proto.Message.
+func (*PubKey) ProtoMessage() {}
+
+// XXX_MessageName returns the proto name of the key, whether or not the name is registered.
+func (*PubKey) XXX_MessageName() string { return pubKeyProtoName }
+
+// Marshal returns the proto encoding of the key.
+func (pubKey *PubKey) Marshal() ([]byte, error) {
+	return appendBytes(nil, 1, pubKey.Key), nil
+}
+
+// MarshalTo writes the proto encoding of the key to the buffer.
+func (pubKey *PubKey) MarshalTo(dAtA []byte) (int, error) {
+	return copy(dAtA, appendBytes(nil, 1, pubKey.Key)), nil
+}
+
+// MarshalToSizedBuffer writes the proto encoding of the key to the end of the buffer.
+func (pubKey *PubKey) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	return marshalToSizedBuffer(dAtA, appendBytes(nil, 1, pubKey.Key)), nil
+}
+
+// Size returns the size of the proto encoding of the key.
+func (pubKey *PubKey) Size() int {
+	return sizeBytes(1, pubKey.Key)
+}
+
+// Unmarshal decodes the proto encoding of the key.
+func (pubKey *PubKey) Unmarshal(dAtA []byte) error {
+	return unmarshalFields(dAtA, func(num protowire.Number, value []byte) error {
+		if num == 1 {
+			pubKey.Key = append([]byte{}, value...)
+		}
+
+		return nil
+	})
+}
+
+// Reset implements proto.Message.
+func (acc *EthAccount) Reset() { *acc = EthAccount{} }
+
+// String implements proto.Message.
+func (acc *EthAccount) String() string {
+	return fmt.Sprintf("EthAccount{%s code_hash:%q}", acc.BaseAccount, acc.CodeHash)
+}
+
+// ProtoMessage implements proto.Message.
+func (*EthAccount) ProtoMessage() {}
+
+// XXX_MessageName returns the proto name of the account, whether or not the name is registered.
+func (*EthAccount) XXX_MessageName() string { return ethAccountProtoName }
+
+// Marshal returns the proto encoding of the account.
+func (acc *EthAccount) Marshal() ([]byte, error) {
+	var dAtA []byte
+	if acc.BaseAccount != nil {
+		base, err := acc.BaseAccount.Marshal()
+		if err != nil {
+			return nil, err
+		}
+
+		dAtA = protowire.AppendTag(dAtA, 1, protowire.BytesType)
+		dAtA = protowire.AppendBytes(dAtA, base)
+	}
+
+	return appendBytes(dAtA, 2, []byte(acc.CodeHash)), nil
+}
+
+// MarshalTo writes the proto encoding of the account to the buffer.
+func (acc *EthAccount) MarshalTo(dAtA []byte) (int, error) {
+	bz, err := acc.Marshal()
+	return copy(dAtA, bz), err
+}
+
+// MarshalToSizedBuffer writes the proto encoding of the account to the end of the buffer.
+func (acc *EthAccount) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	bz, err := acc.Marshal()
+	return marshalToSizedBuffer(dAtA, bz), err
+}
+
+// Size returns the size of the proto encoding of the account.
+func (acc *EthAccount) Size() int {
+	size := sizeBytes(2, []byte(acc.CodeHash))
+	if acc.BaseAccount != nil {
+		size += protowire.SizeTag(1) + protowire.SizeBytes(acc.BaseAccount.Size())
+	}
+
+	return size
+}
+
+// Unmarshal decodes the proto encoding of the account.
+func (acc *EthAccount) Unmarshal(dAtA []byte) error {
+	return unmarshalFields(dAtA, func(num protowire.Number, value []byte) error {
+		switch num {
+		case 1:
+			acc.BaseAccount = &authtypes.BaseAccount{}
+			return acc.BaseAccount.Unmarshal(value)
+		case 2:
+			acc.CodeHash = string(value)
+		}
+
+		return nil
+	})
+}
+
+// appendBytes appends the length delimited field, empty fields are omitted as by proto3.
+func appendBytes(dAtA []byte, num protowire.Number, value []byte) []byte {
+	if len(value) == 0 {
+		return dAtA
+	}
+
+	dAtA = protowire.AppendTag(dAtA, num, protowire.BytesType)
+	return protowire.AppendBytes(dAtA, value)
+}
+
+// sizeBytes returns the encoded size of the length delimited field.
+func sizeBytes(num protowire.Number, value []byte) int {
+	if len(value) == 0 {
+		return 0
+	}
+
+	return protowire.SizeTag(num) + protowire.SizeBytes(len(value))
+}
+
+// marshalToSizedBuffer copies the encoding to the end of the buffer, as gogoproto marshals backwards.
+func marshalToSizedBuffer(dAtA, bz []byte) int {
+	return copy(dAtA[len(dAtA)-len(bz):], bz)
+}
+
+// unmarshalFields calls the field function with the value of each length delimited field
+// of the message, other fields are skipped as none of the types defines them.
+func unmarshalFields(dAtA []byte, field func(num protowire.Number, value []byte) error) error {
+	for len(dAtA) > 0 {
+		num, typ, n := protowire.ConsumeTag(dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		dAtA = dAtA[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, dAtA)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			dAtA = dAtA[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(dAtA)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		if err := field(num, value); err != nil {
+			return err
+		}
+
+		dAtA = dAtA[n:]
+	}
+
+	return nil
+}
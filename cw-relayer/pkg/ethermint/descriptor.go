@@ -0,0 +1,86 @@
+package ethermint
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+)
+
+// File descriptors of the ethermint types, in the gzipped format of generated code. They are
+// used by the SDK tx decoder to reject unknown fields of txs carrying ethsecp256k1 keys.
+var (
+	keysDescriptor = fileDescriptor(
+		"ethermint/crypto/v1/ethsecp256k1/keys.proto",
+		"ethermint.crypto.v1.ethsecp256k1",
+		nil,
+		message("PubKey", field("key", 1, descriptor.FieldDescriptorProto_TYPE_BYTES, "")),
+		message("PrivKey", field("key", 1, descriptor.FieldDescriptorProto_TYPE_BYTES, "")),
+	)
+
+	accountDescriptor = fileDescriptor(
+		"ethermint/types/v1/account.proto",
+		"ethermint.types.v1",
+		[]string{"cosmos/auth/v1beta1/auth.proto"},
+		message(
+			"EthAccount",
+			field("base_account", 1, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".cosmos.auth.v1beta1.BaseAccount"),
+			field("code_hash", 2, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+		),
+	)
+)
+
+// Descriptor returns the file descriptor and the index of the message.
+func (*PubKey) Descriptor() ([]byte, []int) { return keysDescriptor, []int{0} }
+
+// Descriptor returns the file descriptor and the index of the message.
+func (*PrivKey) Descriptor() ([]byte, []int) { return keysDescriptor, []int{1} }
+
+// Descriptor returns the file descriptor and the index of the message.
+func (*EthAccount) Descriptor() ([]byte, []int) { return accountDescriptor, []int{0} }
+
+// fileDescriptor returns the gzipped proto3 file descriptor of the messages.
+func fileDescriptor(name, pkg string, deps []string, messages ...*descriptor.DescriptorProto) []byte {
+	bz, err := proto.Marshal(&descriptor.FileDescriptorProto{
+		Name:        proto.String(name),
+		Package:     proto.String(pkg),
+		Dependency:  deps,
+		MessageType: messages,
+		Syntax:      proto.String("proto3"),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(bz); err != nil {
+		panic(err)
+	}
+
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+
+	return buf.Bytes()
+}
+
+func message(name string, fields ...*descriptor.FieldDescriptorProto) *descriptor.DescriptorProto {
+	return &descriptor.DescriptorProto{Name: proto.String(name), Field: fields}
+}
+
+func field(name string, num int32, typ descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+	f := &descriptor.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(num),
+		Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+
+	if len(typeName) > 0 {
+		f.TypeName = proto.String(typeName)
+	}
+
+	return f
+}
@@ -0,0 +1,201 @@
+package ethermint
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+// Known answers of the private key and message of the web3.js accounts documentation, signed by
+// web3.js and go-ethereum over the keccak256 hash of the message. The proto encodings follow the
+// ethermint keys.proto and account.proto definitions, with the field tags written out by hand.
+const (
+	kaPrivKey   = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	kaMessage   = "\x19Ethereum Signed Message:\n9Some data"
+	kaHash      = "1da44b586eb0729ff70a73c326926f6ed5a25f5b056e7f47fbc6e58d86871655"
+	kaAddress   = "2C7536E3605D9C16A7A3D7B1898E529396A65C23"
+	kaPubKey    = "024e3b81af9c2234cad09d679ce6035ed1392347ce64ce405f5dcd36228a25de6e"
+	kaBech32    = "ethm1936ndcmqtkwpdfar67ccnrjjjwt2vhpr7g2963"
+	kaCodeHash  = "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+	kaSignature = "b91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd" +
+		"6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a029" +
+		"01"
+
+	// PubKey{key}: field 1 bytes
+	kaPubKeyProto = "0a21" + kaPubKey
+
+	// EthAccount{BaseAccount{address, Any{PubKey}, 3, 7}, code hash}
+	kaEthAccountProto = "0a82010a2b6574686d313933366e64636d71746b777064666172363763636e72" +
+		"6a6a6a77743276687072376732393633124f0a282f65746865726d696e742e63" +
+		"727970746f2e76312e657468736563703235366b312e5075624b657912230a21" +
+		"024e3b81af9c2234cad09d679ce6035ed1392347ce64ce405f5dcd36228a25de" +
+		"6e18032007124230786335643234363031383666373233336339323765376462" +
+		"3264636337303363306535303062363533636138323237336237626661643830" +
+		"343564383561343730"
+)
+
+func TestRegisterInterfaces(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	RegisterInterfaces(registry)
+
+	// the types are resolved by the profile registry only, the global proto registry is left untouched
+	for _, name := range []string{privKeyProtoName, pubKeyProtoName, ethAccountProtoName} {
+		require.Nil(t, proto.MessageType(name))
+
+		msg, err := registry.Resolve("/" + name)
+		require.NoError(t, err)
+		require.Equal(t, name, proto.MessageName(msg))
+	}
+}
+
+func TestKnownAnswers(t *testing.T) {
+	key := mustDecodeHex(t, kaPrivKey)
+	privKey := &PrivKey{Key: key}
+	pubKey := privKey.PubKey()
+
+	require.Equal(t, kaHash, hex.EncodeToString(keccak256([]byte(kaMessage))))
+	require.Equal(t, kaPubKey, hex.EncodeToString(pubKey.Bytes()))
+	require.Equal(t, kaAddress, pubKey.Address().String())
+	require.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(keccak256(nil)))
+
+	// signatures are deterministic (RFC 6979) with the recovery id as V
+	signature, err := privKey.Sign([]byte(kaMessage))
+	require.NoError(t, err)
+	require.Equal(t, kaSignature, hex.EncodeToString(signature))
+	require.True(t, pubKey.VerifySignature([]byte(kaMessage), mustDecodeHex(t, kaSignature)))
+
+	bz, err := pubKey.(*PubKey).Marshal()
+	require.NoError(t, err)
+	require.Equal(t, kaPubKeyProto, hex.EncodeToString(bz))
+
+	var decodedKey PubKey
+	require.NoError(t, decodedKey.Unmarshal(mustDecodeHex(t, kaPubKeyProto)))
+	require.True(t, pubKey.Equals(&decodedKey))
+
+	registry := codectypes.NewInterfaceRegistry()
+	authtypes.RegisterInterfaces(registry)
+	RegisterInterfaces(registry)
+
+	baseAccount := authtypes.NewBaseAccount(nil, pubKey, 3, 7)
+	baseAccount.Address = kaBech32
+	bz, err = (&EthAccount{BaseAccount: baseAccount, CodeHash: kaCodeHash}).Marshal()
+	require.NoError(t, err)
+	require.Equal(t, kaEthAccountProto, hex.EncodeToString(bz))
+
+	var account EthAccount
+	require.NoError(t, account.Unmarshal(mustDecodeHex(t, kaEthAccountProto)))
+	require.NoError(t, account.UnpackInterfaces(registry))
+	require.Equal(t, kaBech32, account.BaseAccount.Address)
+	require.Equal(t, uint64(3), account.GetAccountNumber())
+	require.Equal(t, uint64(7), account.GetSequence())
+	require.Equal(t, kaCodeHash, account.CodeHash)
+	require.True(t, pubKey.Equals(account.GetPubKey()))
+}
+
+func TestAddressVectors(t *testing.T) {
+	// published ethereum addresses of the private keys
+	testCases := []struct {
+		privKey string
+		address string
+	}{
+		{privKey: strings.Repeat("0", 63) + "1", address: "7E5F4552091A69125D5DFCB7B8C2659029395BDF"},
+		{privKey: strings.Repeat("0", 63) + "2", address: "2B5AD5C4795C026514F8317C7A215E218DCCD6CF"},
+		{privKey: strings.Repeat("0", 63) + "3", address: "6813EB9362372EEF6200F3B1DBC3F819671CBA69"},
+		{privKey: "0123456789012345678901234567890123456789012345678901234567890123", address: "14791697260E4C9A71F18484C9F997B308E59325"},
+		{privKey: kaPrivKey, address: kaAddress},
+	}
+
+	for _, tc := range testCases {
+		pubKey := (&PrivKey{Key: mustDecodeHex(t, tc.privKey)}).PubKey()
+		require.Equal(t, tc.address, pubKey.Address().String(), tc.privKey)
+	}
+
+	// keccak256, not the NIST sha3-256 of the same input
+	require.Equal(t, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45", hex.EncodeToString(keccak256([]byte("abc"))))
+}
+
+func TestVerifySignature_Rejects(t *testing.T) {
+	pubKey := (&PrivKey{Key: mustDecodeHex(t, kaPrivKey)}).PubKey()
+	signature := mustDecodeHex(t, kaSignature)
+	msg := []byte(kaMessage)
+
+	// the malleated signature (R, N-S) is valid ecdsa but rejected as high S
+	var s secp256k1.ModNScalar
+	require.False(t, s.SetByteSlice(signature[32:64]))
+	s.Negate()
+	malleated := append([]byte{}, signature[:32]...)
+	sBytes := s.Bytes()
+	malleated = append(malleated, sBytes[:]...)
+	require.False(t, pubKey.VerifySignature(msg, malleated))
+
+	require.False(t, pubKey.VerifySignature(msg, signature[:63]))
+	require.False(t, pubKey.VerifySignature(msg, append(signature, 0)))
+	require.False(t, pubKey.VerifySignature(msg, make([]byte, SignatureSize)))
+
+	otherKey := (&PrivKey{Key: mustDecodeHex(t, strings.Repeat("0", 63)+"1")}).PubKey()
+	require.False(t, otherKey.VerifySignature(msg, signature))
+	require.False(t, (&PubKey{Key: []byte{2}}).VerifySignature(msg, signature))
+}
+
+func TestKeys(t *testing.T) {
+	key, err := hex.DecodeString(strings.Repeat("0", 63) + "1")
+	require.NoError(t, err)
+
+	privKey := &PrivKey{Key: key}
+	pubKey := privKey.PubKey()
+	require.Len(t, pubKey.Bytes(), PubKeySize)
+
+	// the ethereum address of the private key 1
+	require.Equal(t, "7E5F4552091A69125D5DFCB7B8C2659029395BDF", pubKey.Address().String())
+
+	signature, err := privKey.Sign([]byte("sign bytes"))
+	require.NoError(t, err)
+	require.Len(t, signature, SignatureSize)
+	require.True(t, pubKey.VerifySignature([]byte("sign bytes"), signature))
+	require.True(t, pubKey.VerifySignature([]byte("sign bytes"), signature[:SignatureSize-1]))
+	require.False(t, pubKey.VerifySignature([]byte("other bytes"), signature))
+}
+
+func TestCodec(t *testing.T) {
+	registry := codectypes.NewInterfaceRegistry()
+	authtypes.RegisterInterfaces(registry)
+	RegisterInterfaces(registry)
+
+	key, err := hex.DecodeString(strings.Repeat("0", 63) + "2")
+	require.NoError(t, err)
+
+	pubKey := (&PrivKey{Key: key}).PubKey()
+	baseAccount := authtypes.NewBaseAccount(pubKey.Address().Bytes(), pubKey, 3, 7)
+	account := &EthAccount{BaseAccount: baseAccount, CodeHash: "0xc5d2"}
+
+	any, err := codectypes.NewAnyWithValue(account)
+	require.NoError(t, err)
+	require.Equal(t, "/ethermint.types.v1.EthAccount", any.TypeUrl)
+
+	bz, err := any.Marshal()
+	require.NoError(t, err)
+
+	var decodedAny codectypes.Any
+	require.NoError(t, decodedAny.Unmarshal(bz))
+
+	var decoded authtypes.AccountI
+	require.NoError(t, registry.UnpackAny(&decodedAny, &decoded))
+	require.Equal(t, uint64(3), decoded.GetAccountNumber())
+	require.Equal(t, uint64(7), decoded.GetSequence())
+	require.Equal(t, baseAccount.GetAddress(), decoded.GetAddress())
+	require.True(t, pubKey.Equals(decoded.GetPubKey()))
+	require.Equal(t, "0xc5d2", decoded.(*EthAccount).CodeHash)
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	bz, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return bz
+}
@@ -0,0 +1,187 @@
+package ethermint
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// KeyType is the key type of ethsecp256k1 keys.
+	KeyType = "eth_secp256k1"
+
+	PrivKeySize   = 32
+	PubKeySize    = 33
+	SignatureSize = 65
+
+	PrivKeyName = "ethermint/PrivKeyEthSecp256k1"
+	PubKeyName  = "ethermint/PubKeyEthSecp256k1"
+)
+
+var (
+	_ cryptotypes.PrivKey = &PrivKey{}
+	_ cryptotypes.PubKey  = &PubKey{}
+
+	// EthSecp256k1 is the keyring signing algorithm of ethsecp256k1 keys. Keys are derived with
+	// the BIP32 derivation of secp256k1 keys, with the hd path of the key, e.g. coin type 60.
+	EthSecp256k1 = ethSecp256k1Algo{}
+)
+
+type (
+	// PrivKey is an ethsecp256k1 private key, compatible with the ethermint key of the same name.
+	PrivKey struct {
+		Key []byte
+	}
+
+	// PubKey is a compressed ethsecp256k1 public key, compatible with the ethermint key of the same name.
+	// Addresses are derived as ethereum addresses, and signatures are signed over the keccak256 hash.
+	PubKey struct {
+		Key []byte
+	}
+
+	ethSecp256k1Algo struct{}
+)
+
+// Name returns the key type of the algorithm.
+func (ethSecp256k1Algo) Name() hd.PubKeyType {
+	return KeyType
+}
+
+// Derive derives the private key bytes of the mnemonic and hd path.
+func (ethSecp256k1Algo) Derive() hd.DeriveFn {
+	return hd.Secp256k1.Derive()
+}
+
+// Generate returns the private key of the derived bytes.
+func (ethSecp256k1Algo) Generate() hd.GenerateFn {
+	return func(bz []byte) cryptotypes.PrivKey {
+		key := make([]byte, PrivKeySize)
+		copy(key, bz)
+
+		return &PrivKey{Key: key}
+	}
+}
+
+// Bytes returns the private key bytes.
+func (privKey *PrivKey) Bytes() []byte {
+	return privKey.Key
+}
+
+// PubKey returns the compressed public key of the private key.
+func (privKey *PrivKey) PubKey() cryptotypes.PubKey {
+	return &PubKey{Key: secp256k1.PrivKeyFromBytes(privKey.Key).PubKey().SerializeCompressed()}
+}
+
+// Equals returns true if the keys are equal, in constant time.
+func (privKey *PrivKey) Equals(other cryptotypes.LedgerPrivKey) bool {
+	return privKey.Type() == other.Type() && subtle.ConstantTimeCompare(privKey.Bytes(), other.Bytes()) == 1
+}
+
+// Type returns the key type.
+func (privKey *PrivKey) Type() string {
+	return KeyType
+}
+
+// Sign returns the [R || S || V] signature of the keccak256 hash of the message.
+func (privKey *PrivKey) Sign(msg []byte) ([]byte, error) {
+	if len(privKey.Key) != PrivKeySize {
+		return nil, fmt.Errorf("invalid private key length %d", len(privKey.Key))
+	}
+
+	// the compact signature is [V || R || S] with V = 27 + recovery id
+	compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(privKey.Key), keccak256(msg), false)
+	return append(compact[1:], compact[0]-27), nil
+}
+
+// MarshalAmino overrides Amino binary marshalling.
+func (privKey PrivKey) MarshalAmino() ([]byte, error) {
+	return privKey.Key, nil
+}
+
+// UnmarshalAmino overrides Amino binary marshalling.
+func (privKey *PrivKey) UnmarshalAmino(bz []byte) error {
+	if len(bz) != PrivKeySize {
+		return fmt.Errorf("invalid private key length %d", len(bz))
+	}
+
+	privKey.Key = bz
+	return nil
+}
+
+// Address returns the ethereum address of the public key, i.e. the last 20 bytes
+// of the keccak256 hash of the uncompressed key.
+func (pubKey *PubKey) Address() cryptotypes.Address {
+	key, err := secp256k1.ParsePubKey(pubKey.Key)
+	if err != nil {
+		return nil
+	}
+
+	return keccak256(key.SerializeUncompressed()[1:])[12:]
+}
+
+// Bytes returns the compressed public key bytes.
+func (pubKey *PubKey) Bytes() []byte {
+	return pubKey.Key
+}
+
+// Equals returns true if the keys are equal.
+func (pubKey *PubKey) Equals(other cryptotypes.PubKey) bool {
+	return pubKey.Type() == other.Type() && bytes.Equal(pubKey.Bytes(), other.Bytes())
+}
+
+// Type returns the key type.
+func (pubKey *PubKey) Type() string {
+	return KeyType
+}
+
+// VerifySignature verifies the [R || S || V] or [R || S] signature of the keccak256 hash of the message,
+// malleable signatures with a high S value are rejected.
+func (pubKey *PubKey) VerifySignature(msg, sig []byte) bool {
+	if len(sig) == SignatureSize {
+		sig = sig[:SignatureSize-1]
+	}
+
+	if len(sig) != SignatureSize-1 {
+		return false
+	}
+
+	key, err := secp256k1.ParsePubKey(pubKey.Key)
+	if err != nil {
+		return false
+	}
+
+	var r, s secp256k1.ModNScalar
+	if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:]) || s.IsOverHalfOrder() {
+		return false
+	}
+
+	return ecdsa.NewSignature(&r, &s).Verify(keccak256(msg), key)
+}
+
+// MarshalAmino overrides Amino binary marshalling.
+func (pubKey PubKey) MarshalAmino() ([]byte, error) {
+	return pubKey.Key, nil
+}
+
+// UnmarshalAmino overrides Amino binary marshalling.
+func (pubKey *PubKey) UnmarshalAmino(bz []byte) error {
+	if len(bz) != PubKeySize {
+		return fmt.Errorf("invalid public key length %d", len(bz))
+	}
+
+	pubKey.Key = bz
+	return nil
+}
+
+func keccak256(bz []byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(bz)
+
+	return hash.Sum(nil)
+}
@@ -0,0 +1,164 @@
+package signer
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/rs/zerolog"
+
+	"github.com/ojo-network/cw-relayer/pkg/httputil"
+)
+
+const (
+	bearerPrefix = "Bearer "
+	unixScheme   = "unix://"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// Server is a reference remote signer, signing requests with the keys of a local keyring.
+// Requests must carry the bearer token if it is set. It is meant for local tests, a production
+// signer should run in a hardened process and restrict what it signs.
+type Server struct {
+	logger  zerolog.Logger
+	keyring keyring.Keyring
+	prefix  string
+	token   string
+	mux     *http.ServeMux
+}
+
+// NewServer returns a remote signer serving the keys of the keyring, addresses
+// of requests must have the bech32 account prefix.
+func NewServer(logger zerolog.Logger, kr keyring.Keyring, prefix, token string) *Server {
+	s := &Server{
+		logger:  logger.With().Str("module", "signer").Logger(),
+		keyring: kr,
+		prefix:  prefix,
+		token:   token,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc(PathPubKey, s.handlePubKey)
+	s.mux.HandleFunc(PathSign, s.handleSign)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httputil.RespondWithError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	if !s.authorized(r) {
+		httputil.RespondWithError(w, http.StatusUnauthorized, errUnauthorized)
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handlePubKey(w http.ResponseWriter, r *http.Request) {
+	var req PubKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	address, err := sdk.GetFromBech32(req.Address, s.prefix)
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	record, err := s.keyring.KeyByAddress(sdk.AccAddress(address))
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	pubKey, err := record.GetPubKey()
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	httputil.RespondWithJSON(w, http.StatusOK, EncodePubKey(req.Address, pubKey))
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	var req SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	address, err := sdk.GetFromBech32(req.Address, s.prefix)
+	if err != nil {
+		httputil.RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	signature, _, err := s.keyring.SignByAddress(sdk.AccAddress(address), req.SignBytes)
+	if err != nil {
+		s.logger.Err(err).Str("address", req.Address).Msg("error signing request")
+		httputil.RespondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s.logger.Info().Str("address", req.Address).Msg("signed request")
+	httputil.RespondWithJSON(w, http.StatusOK, SignResponse{Signature: signature})
+}
+
+// authorized returns true if the request carries the bearer token, or if no token is set.
+func (s *Server) authorized(r *http.Request) bool {
+	if len(s.token) == 0 {
+		return true
+	}
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return false
+	}
+
+	token := strings.TrimPrefix(header, bearerPrefix)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// Listen listens on the unix socket of a unix:// address, or on the tcp host:port address otherwise.
+// A token is required unless the address is a unix socket or a loopback address.
+func Listen(address, token string) (net.Listener, error) {
+	if strings.HasPrefix(address, unixScheme) {
+		return net.Listen("unix", strings.TrimPrefix(address, unixScheme))
+	}
+
+	if len(token) == 0 && !isLoopback(address) {
+		return nil, fmt.Errorf("a token is required to listen on the non loopback address %s", address)
+	}
+
+	return net.Listen("tcp", address)
+}
+
+// isLoopback returns true if the host of the address is localhost or a loopback ip,
+// an empty host listens on all interfaces.
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,63 @@
+package signer
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen(t *testing.T) {
+	testCases := []struct {
+		address   string
+		token     string
+		expectErr bool
+	}{
+		{address: "127.0.0.1:0"},
+		{address: "localhost:0"},
+		{address: "unix://" + filepath.Join(t.TempDir(), "signer.sock")},
+		{address: ":0", expectErr: true},
+		{address: "0.0.0.0:0", expectErr: true},
+		{address: "0.0.0.0:0", token: "token"},
+	}
+
+	for _, tc := range testCases {
+		listener, err := Listen(tc.address, tc.token)
+		if tc.expectErr {
+			require.Error(t, err, tc.address)
+			continue
+		}
+
+		require.NoError(t, err, tc.address)
+		listener.Close()
+	}
+}
+
+func TestServer_Authorized(t *testing.T) {
+	server := NewServer(zerolog.Nop(), nil, "wasm", "token")
+
+	testCases := []struct {
+		authorization string
+		authorized    bool
+	}{
+		{authorization: "Bearer token", authorized: true},
+		{authorization: "token"},
+		{authorization: "Bearer other"},
+		{authorization: "Basic token"},
+		{},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("POST", PathSign, nil)
+		if len(tc.authorization) > 0 {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+
+		require.Equal(t, tc.authorized, server.authorized(req), tc.authorization)
+	}
+
+	// requests are authorized without a token
+	require.True(t, NewServer(zerolog.Nop(), nil, "wasm", "").authorized(httptest.NewRequest("POST", PathSign, nil)))
+}
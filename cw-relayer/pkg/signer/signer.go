@@ -0,0 +1,78 @@
+package signer
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/ethermint"
+)
+
+// Paths of the remote signing protocol. Both endpoints accept a JSON POST request, and respond
+// with a JSON response or an httputil.ErrResponse. Byte fields are base64 encoded.
+const (
+	PathPubKey = "/pubkey"
+	PathSign   = "/sign"
+
+	// KeyTypeSecp256k1 is the key type of secp256k1 public keys.
+	KeyTypeSecp256k1 = "secp256k1"
+	// KeyTypeEthSecp256k1 is the key type of ethsecp256k1 public keys of ethermint chains.
+	KeyTypeEthSecp256k1 = ethermint.KeyType
+)
+
+type (
+	// PubKeyRequest requests the public key of the signer key of the address.
+	PubKeyRequest struct {
+		Address string `json:"address"`
+	}
+
+	// PubKeyResponse defines the public key of the address by key type and raw key bytes.
+	PubKeyResponse struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Key     []byte `json:"key"`
+	}
+
+	// SignRequest requests a signature of the sign bytes with the signer key of the address.
+	SignRequest struct {
+		Address   string `json:"address"`
+		SignBytes []byte `json:"sign_bytes"`
+	}
+
+	// SignResponse defines the signature of the sign bytes.
+	SignResponse struct {
+		Signature []byte `json:"signature"`
+	}
+)
+
+// EncodePubKey returns the public key response of the address.
+func EncodePubKey(address string, pubKey cryptotypes.PubKey) PubKeyResponse {
+	return PubKeyResponse{
+		Address: address,
+		Type:    pubKey.Type(),
+		Key:     pubKey.Bytes(),
+	}
+}
+
+// DecodePubKey returns the public key of the response.
+func DecodePubKey(resp PubKeyResponse) (cryptotypes.PubKey, error) {
+	switch resp.Type {
+	case KeyTypeSecp256k1:
+		if len(resp.Key) != secp256k1.PubKeySize {
+			return nil, fmt.Errorf("invalid secp256k1 public key length %d", len(resp.Key))
+		}
+
+		return &secp256k1.PubKey{Key: resp.Key}, nil
+
+	case KeyTypeEthSecp256k1:
+		if len(resp.Key) != ethermint.PubKeySize {
+			return nil, fmt.Errorf("invalid ethsecp256k1 public key length %d", len(resp.Key))
+		}
+
+		return &ethermint.PubKey{Key: resp.Key}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported public key type %s", resp.Type)
+	}
+}
@@ -0,0 +1,65 @@
+package relayer
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+)
+
+// Relayer alert names.
+const (
+	AlertMissedThreshold = "missed_threshold"
+	AlertSwitchStorm     = "rpc_switch_storm"
+	AlertStalePrices     = "stale_prices"
+	AlertMissingDenoms   = "missing_denoms"
+	AlertOutlier         = "outlier"
+	AlertOutlierMedians  = "outlier_stale_medians"
+	AlertVerify          = "verify_mismatch"
+	AlertLowBalance      = "low_balance"
+	AlertTopUpCap        = "top_up_cap"
+	AlertFeeGrant        = "fee_grant"
+	AlertFeeGrantExpiry  = "fee_grant_expiry"
+	AlertFeeGrantSpend   = "fee_grant_spend_limit"
+)
+
+// AlertConfig defines the alerter of relayer incidents, alerts are dropped if Alerter is nil.
+// A switch storm is alerted when the ojo query endpoints are switched SwitchStormThreshold
+// times within SwitchStormWindow, it is disabled if the threshold is zero.
+type AlertConfig struct {
+	Alerter              *alert.Alerter
+	SwitchStormThreshold int
+	SwitchStormWindow    time.Duration
+}
+
+// alert fires a relayer alert.
+func (r *Relayer) alert(name, severity, message string, fields map[string]string) {
+	r.alerts.Alerter.Fire(alert.Alert{
+		Name:     name,
+		Severity: severity,
+		Message:  message,
+		Fields:   fields,
+	})
+}
+
+// resolveAlert clears the alert windows of a recovered incident.
+func (r *Relayer) resolveAlert(name string) {
+	r.alerts.Alerter.Resolve(name)
+}
+
+// checkSwitchStorm alerts if the ojo query endpoints were switched too often within the window.
+func (r *Relayer) checkSwitchStorm() {
+	if r.alerts.SwitchStormThreshold <= 0 || r.pool == nil {
+		return
+	}
+
+	switches := r.pool.Switches(r.alerts.SwitchStormWindow)
+	if switches < r.alerts.SwitchStormThreshold {
+		return
+	}
+
+	r.alert(AlertSwitchStorm, alert.SeverityWarning, "ojo query endpoints are switched repeatedly", map[string]string{
+		"switches": strconv.Itoa(switches),
+		"window":   r.alerts.SwitchStormWindow.String(),
+	})
+}
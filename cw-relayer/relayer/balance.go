@@ -0,0 +1,212 @@
+package relayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+)
+
+const topUpCapWindow = 24 * time.Hour
+
+// BalanceConfig defines the monitoring of the relayer account balance in Denom, which is queried
+// every Interval and alerted below the Warning and Critical thresholds. Monitoring is disabled
+// if Denom is empty. The balance is topped up from the funder account if TopUp is set.
+type BalanceConfig struct {
+	Denom    string
+	Interval time.Duration
+	Warning  types.Int
+	Critical types.Int
+	TopUp    TopUpConfig
+}
+
+// TopUpConfig defines the top up of the relayer account. Amount is sent from the Funder key in the
+// relayer keyring when the balance is below Floor, and at most DailyCap is sent within 24 hours.
+// The sends of the funder within the last 24 hours are read from the chain on startup, and no top up
+// is sent until they are read. Top ups are disabled if Funder is empty.
+type TopUpConfig struct {
+	Funder   types.AccAddress
+	Floor    types.Int
+	Amount   types.Int
+	DailyCap types.Int
+}
+
+// topUp is a top up sent to the relayer account.
+type topUp struct {
+	amount types.Int
+	time   time.Time
+}
+
+// enabled returns true if the relayer account is topped up.
+func (c TopUpConfig) enabled() bool {
+	return !c.Funder.Empty() && !c.Amount.IsNil() && c.Amount.IsPositive()
+}
+
+// monitorBalance checks the relayer balance every interval until the context is done.
+func (r *Relayer) monitorBalance(ctx context.Context) {
+	ticker := time.NewTicker(r.balance.Interval)
+	defer ticker.Stop()
+
+	var topUps []topUp
+	seeded := false
+	for {
+		if r.balance.TopUp.enabled() && !seeded {
+			var err error
+			if topUps, err = r.seedTopUps(); err != nil {
+				r.logger.Err(err).Msg("error reading recent top ups, holding top ups until the daily cap window is read")
+			} else {
+				seeded = true
+			}
+		}
+
+		topUps = r.checkBalance(ctx, topUps, seeded)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkBalance queries the relayer balance, alerts on the balance thresholds and tops up the balance
+// if it is below the floor and the top ups of the daily cap window are seeded. It returns the top ups
+// sent within the daily cap window.
+func (r *Relayer) checkBalance(ctx context.Context, topUps []topUp, seeded bool) []topUp {
+	address := r.relayerClient.RelayerAddr
+	balance, err := r.relayerClient.Balance(ctx, r.queryTimeout, address, r.balance.Denom)
+	if err != nil {
+		telemetry.IncrCounter(1, "failure", "balance")
+		r.logger.Err(err).Msg("error querying relayer balance")
+		return topUps
+	}
+
+	telemetry.SetGauge(float32(types.NewDecFromInt(balance.Amount).MustFloat64()), "balance", r.balance.Denom)
+
+	fields := map[string]string{
+		"address": r.relayerClient.RelayerAddrString,
+		"balance": balance.String(),
+	}
+
+	switch severity := balanceSeverity(balance.Amount, r.balance); severity {
+	case "":
+		r.resolveAlert(AlertLowBalance)
+	default:
+		r.logger.Warn().Str("balance", balance.String()).Str("severity", severity).Msg("relayer balance is low")
+		r.alert(AlertLowBalance, severity, "relayer balance is low", fields)
+	}
+
+	if !r.balance.TopUp.enabled() || !seeded {
+		return topUps
+	}
+
+	topUps = recentTopUps(topUps, time.Now())
+	amount := topUpAmount(balance.Amount, r.balance.TopUp, topUps)
+	if amount.IsZero() {
+		if belowFloor(balance.Amount, r.balance.TopUp) {
+			r.logger.Warn().Str("balance", balance.String()).Msg("relayer top up daily cap reached")
+			r.alert(AlertTopUpCap, alert.SeverityCritical, "relayer top up daily cap reached", fields)
+		}
+
+		return topUps
+	}
+
+	coin := types.NewCoin(r.balance.Denom, amount)
+	resp, err := r.relayerClient.Send(r.balance.TopUp.Funder, types.NewCoins(coin))
+	if err != nil {
+		telemetry.IncrCounter(1, "failure", "top_up")
+		r.logger.Err(err).Str("funder", r.relayerClient.AddressCodec.Encode(r.balance.TopUp.Funder)).Msg("error topping up relayer balance")
+		return topUps
+	}
+
+	telemetry.IncrCounter(1, "new", "top_up")
+	r.logger.Info().
+		Str("funder", r.relayerClient.AddressCodec.Encode(r.balance.TopUp.Funder)).
+		Str("amount", coin.String()).
+		Str("tx_hash", resp.TxHash).
+		Msg("topped up relayer balance")
+
+	// wait for the top up to be included before the next balance check
+	if _, err := r.relayerClient.WaitForTx(ctx, resp.TxHash, r.balance.Interval); err != nil {
+		r.logger.Err(err).Str("tx_hash", resp.TxHash).Msg("top up tx not included")
+	}
+
+	return append(topUps, topUp{amount: amount, time: time.Now()})
+}
+
+// seedTopUps returns the sends of the funder to the relayer within the daily cap window, so that
+// the daily cap holds across restarts.
+func (r *Relayer) seedTopUps() ([]topUp, error) {
+	now := time.Now()
+	transfers, err := r.relayerClient.RecentSends(r.balance.TopUp.Funder, r.balance.Denom, now.Add(-topUpCapWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	topUps := make([]topUp, len(transfers))
+	sent := types.ZeroInt()
+	for i, transfer := range transfers {
+		topUps[i] = topUp{amount: transfer.Amount, time: transfer.Time}
+		sent = sent.Add(transfer.Amount)
+	}
+
+	r.logger.Info().Int("top ups", len(topUps)).Str("sent", sent.String()).Msg("read recent relayer top ups")
+
+	return topUps, nil
+}
+
+// balanceSeverity returns the alert severity of the balance, or an empty string if it is above the thresholds.
+func balanceSeverity(balance types.Int, config BalanceConfig) string {
+	switch {
+	case !config.Critical.IsNil() && balance.LT(config.Critical):
+		return alert.SeverityCritical
+	case !config.Warning.IsNil() && balance.LT(config.Warning):
+		return alert.SeverityWarning
+	default:
+		return ""
+	}
+}
+
+// belowFloor returns true if the balance is below the top up floor.
+func belowFloor(balance types.Int, config TopUpConfig) bool {
+	return !config.Floor.IsNil() && balance.LT(config.Floor)
+}
+
+// topUpAmount returns the amount to top up the balance with, limited by the remaining daily cap.
+// The amount is zero if the balance is not below the floor or the daily cap is reached.
+func topUpAmount(balance types.Int, config TopUpConfig, topUps []topUp) types.Int {
+	if !belowFloor(balance, config) {
+		return types.ZeroInt()
+	}
+
+	amount := config.Amount
+	if config.DailyCap.IsNil() || !config.DailyCap.IsPositive() {
+		return amount
+	}
+
+	remaining := config.DailyCap
+	for _, sent := range topUps {
+		remaining = remaining.Sub(sent.amount)
+	}
+
+	if !remaining.IsPositive() {
+		return types.ZeroInt()
+	}
+
+	return types.MinInt(amount, remaining)
+}
+
+// recentTopUps returns the top ups sent within the daily cap window.
+func recentTopUps(topUps []topUp, now time.Time) []topUp {
+	var recent []topUp
+	for _, sent := range topUps {
+		if now.Sub(sent.time) < topUpCapWindow {
+			recent = append(recent, sent)
+		}
+	}
+
+	return recent
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Bech32Codec encodes and decodes account addresses with the bech32 prefix of a chain, independently
+// of the global sdk config, so that clients of chains with different prefixes can be used in one process.
+type Bech32Codec struct {
+	Prefix string
+}
+
+// Encode returns the bech32 address, or an empty string if the address is empty.
+func (c Bech32Codec) Encode(address sdk.AccAddress) string {
+	if address.Empty() {
+		return ""
+	}
+
+	encoded, err := bech32.ConvertAndEncode(c.Prefix, address)
+	if err != nil {
+		// the prefix is validated by the client constructor
+		panic(err)
+	}
+
+	return encoded
+}
+
+// Decode returns the account address of the bech32 address.
+func (c Bech32Codec) Decode(address string) (sdk.AccAddress, error) {
+	decoded, err := sdk.GetFromBech32(address, c.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sdk.VerifyAddressFormat(decoded); err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// accountRetriever queries accounts by their bech32 address of the codec prefix.
+//
+// Note, accountRetriever is copied from the SDK AccountRetriever, which encodes
+// addresses with the global sdk config.
+type accountRetriever struct {
+	codec Bech32Codec
+}
+
+var _ client.AccountRetriever = accountRetriever{}
+
+// GetAccount queries for an account given an address.
+func (ar accountRetriever) GetAccount(clientCtx client.Context, addr sdk.AccAddress) (client.Account, error) {
+	account, _, err := ar.GetAccountWithHeight(clientCtx, addr)
+	return account, err
+}
+
+// GetAccountWithHeight queries for an account given an address, and returns the height of the query.
+func (ar accountRetriever) GetAccountWithHeight(clientCtx client.Context, addr sdk.AccAddress) (client.Account, int64, error) {
+	var header metadata.MD
+
+	queryClient := authtypes.NewQueryClient(clientCtx)
+	res, err := queryClient.Account(
+		context.Background(),
+		&authtypes.QueryAccountRequest{Address: ar.codec.Encode(addr)},
+		grpc.Header(&header),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	blockHeight := header.Get(grpctypes.GRPCBlockHeightHeader)
+	if l := len(blockHeight); l != 1 {
+		return nil, 0, fmt.Errorf("unexpected '%s' header length; got %d, expected: %d", grpctypes.GRPCBlockHeightHeader, l, 1)
+	}
+
+	height, err := strconv.ParseInt(blockHeight[0], 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse block height: %w", err)
+	}
+
+	var acc authtypes.AccountI
+	if err := clientCtx.InterfaceRegistry.UnpackAny(res.Account, &acc); err != nil {
+		return nil, 0, err
+	}
+
+	return acc, height, nil
+}
+
+// EnsureExists returns an error if no account exists for the given address.
+func (ar accountRetriever) EnsureExists(clientCtx client.Context, addr sdk.AccAddress) error {
+	if _, err := ar.GetAccount(clientCtx, addr); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetAccountNumberSequence returns the account number and sequence of the account.
+func (ar accountRetriever) GetAccountNumberSequence(clientCtx client.Context, addr sdk.AccAddress) (uint64, uint64, error) {
+	acc, err := ar.GetAccount(clientCtx, addr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return acc.GetAccountNumber(), acc.GetSequence(), nil
+}
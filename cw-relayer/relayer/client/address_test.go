@@ -0,0 +1,31 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBech32Codec(t *testing.T) {
+	address := sdk.AccAddress("relayer_address_bytes")
+	wasm := Bech32Codec{Prefix: "wasm"}
+	juno := Bech32Codec{Prefix: "juno"}
+
+	// codecs of different prefixes are used side by side
+	encoded := wasm.Encode(address)
+	require.Equal(t, "wasm", encoded[:4])
+	require.Equal(t, "juno", juno.Encode(address)[:4])
+
+	decoded, err := wasm.Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, address, decoded)
+
+	_, err = juno.Decode(encoded)
+	require.Error(t, err)
+
+	_, err = wasm.Decode("")
+	require.Error(t, err)
+
+	require.Empty(t, wasm.Encode(nil))
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+)
+
+// SenderAddress returns the sender of the contract messages, which is the authz granter
+// if the relayer executes messages on behalf of a granter, or the relayer address otherwise.
+func (oc RelayerClient) SenderAddress() string {
+	if !oc.authzGranter.Empty() {
+		return oc.AddressCodec.Encode(oc.authzGranter)
+	}
+
+	return oc.RelayerAddrString
+}
+
+// wrapMsgs wraps the messages in an authz MsgExec signed by the relayer as grantee,
+// the messages are returned unchanged if no authz granter is set.
+func (oc RelayerClient) wrapMsgs(msgs []sdk.Msg) []sdk.Msg {
+	if oc.authzGranter.Empty() {
+		return msgs
+	}
+
+	msgExec := authz.NewMsgExec(oc.RelayerAddr, msgs)
+	msgExec.Grantee = oc.RelayerAddrString
+
+	return []sdk.Msg{&msgExec}
+}
+
+// checkAuthzGrant returns an error if the authz granter has not granted the relayer
+// to execute contract messages, or if the grant has expired.
+func (oc RelayerClient) checkAuthzGrant(ctx context.Context, timeout time.Duration) error {
+	grpcConn, err := oc.QueryRpc.Dial()
+	if err != nil {
+		return err
+	}
+
+	defer grpcConn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	msgType := sdk.MsgTypeURL(&wasmtypes.MsgExecuteContract{})
+	resp, err := authz.NewQueryClient(grpcConn).Grants(ctx, &authz.QueryGrantsRequest{
+		Granter:    oc.AddressCodec.Encode(oc.authzGranter),
+		Grantee:    oc.RelayerAddrString,
+		MsgTypeUrl: msgType,
+	})
+	if err != nil {
+		return fmt.Errorf("error querying authz grant of %s: %w", msgType, err)
+	}
+
+	for _, grant := range resp.Grants {
+		if grant.Expiration == nil || grant.Expiration.After(time.Now()) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no authz grant of %s from %s to %s", msgType, oc.SenderAddress(), oc.RelayerAddrString)
+}
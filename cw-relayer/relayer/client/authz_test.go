@@ -0,0 +1,33 @@
+package client
+
+import (
+	"testing"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapMsgs(t *testing.T) {
+	relayer := sdk.AccAddress("relayer")
+	granter := sdk.AccAddress("granter")
+	codec := Bech32Codec{Prefix: "wasm"}
+	msgs := []sdk.Msg{&wasmtypes.MsgExecuteContract{Sender: codec.Encode(granter)}}
+
+	client := RelayerClient{RelayerAddr: relayer, RelayerAddrString: codec.Encode(relayer), AddressCodec: codec}
+	require.Equal(t, codec.Encode(relayer), client.SenderAddress())
+	require.Equal(t, msgs, client.wrapMsgs(msgs))
+
+	// messages are executed on behalf of the granter, signed by the relayer
+	client.authzGranter = granter
+	require.Equal(t, codec.Encode(granter), client.SenderAddress())
+
+	wrapped := client.wrapMsgs(msgs)
+	require.Len(t, wrapped, 1)
+
+	msgExec, ok := wrapped[0].(*authz.MsgExec)
+	require.True(t, ok)
+	require.Equal(t, codec.Encode(relayer), msgExec.Grantee)
+	require.Len(t, msgExec.Msgs, 1)
+}
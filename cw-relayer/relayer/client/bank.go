@@ -0,0 +1,103 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// maxRecentSends is the number of latest txs searched for sends to the relayer.
+const maxRecentSends = 100
+
+// Transfer is an amount sent to the relayer account.
+type Transfer struct {
+	Amount sdk.Int
+	Time   time.Time
+}
+
+// RecentSends returns the amounts of denom sent from the sender to the relayer account by txs included
+// since the given time. It requires the tx indexer of the tendermint rpc, and searches the latest
+// maxRecentSends txs only.
+func (oc RelayerClient) RecentSends(sender sdk.AccAddress, denom string, since time.Time) ([]Transfer, error) {
+	clientCtx, err := oc.CreateClientContext()
+	if err != nil {
+		return nil, err
+	}
+
+	senderAddr := oc.AddressCodec.Encode(sender)
+	events := []string{
+		fmt.Sprintf("%s.%s='%s'", banktypes.EventTypeTransfer, sdk.AttributeKeySender, senderAddr),
+		fmt.Sprintf("%s.%s='%s'", banktypes.EventTypeTransfer, banktypes.AttributeKeyRecipient, oc.RelayerAddrString),
+	}
+
+	result, err := authtx.QueryTxsByEvents(clientCtx, events, 1, maxRecentSends, "desc")
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []Transfer
+	for _, tx := range result.Txs {
+		if tx.Code != 0 {
+			continue
+		}
+
+		txTime, err := time.Parse(time.RFC3339, tx.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s: %w", tx.TxHash, err)
+		}
+
+		if txTime.Before(since) {
+			continue
+		}
+
+		amount, err := sentAmount(tx.Logs, senderAddr, oc.RelayerAddrString, denom)
+		if err != nil {
+			return nil, fmt.Errorf("tx %s: %w", tx.TxHash, err)
+		}
+
+		if amount.IsPositive() {
+			transfers = append(transfers, Transfer{Amount: amount, Time: txTime})
+		}
+	}
+
+	return transfers, nil
+}
+
+// sentAmount returns the amount of denom transferred from the sender to the recipient in the
+// message logs of a tx. The attributes of a transfer event are ordered recipient, sender, amount.
+func sentAmount(logs sdk.ABCIMessageLogs, sender, recipient, denom string) (sdk.Int, error) {
+	total := sdk.ZeroInt()
+	for _, log := range logs {
+		for _, event := range log.Events {
+			if event.Type != banktypes.EventTypeTransfer {
+				continue
+			}
+
+			var from, to string
+			for _, attribute := range event.Attributes {
+				switch attribute.Key {
+				case banktypes.AttributeKeyRecipient:
+					to = attribute.Value
+				case sdk.AttributeKeySender:
+					from = attribute.Value
+				case sdk.AttributeKeyAmount:
+					if from != sender || to != recipient {
+						continue
+					}
+
+					coins, err := sdk.ParseCoinsNormalized(attribute.Value)
+					if err != nil {
+						return sdk.Int{}, err
+					}
+
+					total = total.Add(coins.AmountOf(denom))
+				}
+			}
+		}
+	}
+
+	return total, nil
+}
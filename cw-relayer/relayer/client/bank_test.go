@@ -0,0 +1,44 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentAmount(t *testing.T) {
+	transfer := func(recipient, sender, amount string) []sdk.Attribute {
+		return []sdk.Attribute{
+			{Key: "recipient", Value: recipient},
+			{Key: "sender", Value: sender},
+			{Key: "amount", Value: amount},
+		}
+	}
+
+	var attributes []sdk.Attribute
+	attributes = append(attributes, transfer("relayer", "funder", "100stake,5ojo")...)
+	attributes = append(attributes, transfer("other", "funder", "50stake")...)
+	attributes = append(attributes, transfer("relayer", "other", "20stake")...)
+	attributes = append(attributes, transfer("relayer", "funder", "30stake")...)
+
+	logs := sdk.ABCIMessageLogs{
+		{Events: sdk.StringEvents{
+			{Type: "message", Attributes: []sdk.Attribute{{Key: "sender", Value: "funder"}}},
+			{Type: "transfer", Attributes: attributes},
+		}},
+	}
+
+	amount, err := sentAmount(logs, "funder", "relayer", "stake")
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewInt(130), amount)
+
+	amount, err = sentAmount(logs, "funder", "relayer", "uatom")
+	require.NoError(t, err)
+	require.True(t, amount.IsZero())
+
+	_, err = sentAmount(sdk.ABCIMessageLogs{{Events: sdk.StringEvents{
+		{Type: "transfer", Attributes: transfer("relayer", "funder", "invalid")},
+	}}}, "funder", "relayer", "stake")
+	require.Error(t, err)
+}
@@ -7,10 +7,10 @@ import (
 	"sync"
 	"time"
 
+	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/rs/zerolog"
-	tmrpcclient "github.com/tendermint/tendermint/rpc/client"
-	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
-	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
 )
 
 var (
@@ -32,11 +32,12 @@ type ChainHeight struct {
 	lastBlockTimestamp   time.Time
 }
 
-// NewChainHeight returns a new ChainHeight struct that
-// starts a new goroutine subscribed to EventNewBlockHeader.
+// NewChainHeight returns a new ChainHeight struct that starts a new goroutine subscribed
+// to EventNewBlockHeader over a websocket connection to the tendermint rpc.
 func NewChainHeight(
 	ctx context.Context,
-	rpcClient tmrpcclient.Client,
+	rpc endpoint.Endpoint,
+	timeout time.Duration,
 	logger zerolog.Logger,
 	initialHeight int64,
 	initialTimeStamp time.Time,
@@ -45,26 +46,20 @@ func NewChainHeight(
 		return nil, fmt.Errorf("expected positive initial block height")
 	}
 
-	if !rpcClient.IsRunning() {
-		if err := rpcClient.Start(); err != nil {
-			return nil, err
-		}
-	}
-
-	newBlockHeaderSubscription, err := rpcClient.Subscribe(
-		ctx, tmtypes.EventNewBlockHeader, queryEventNewBlockHeader.String())
+	logger = logger.With().Str("relayer_client", "chain_height").Logger()
+	subscription, err := subscribeEvents(ctx, logger, rpc, queryEventNewBlockHeader.String(), timeout)
 	if err != nil {
 		return nil, err
 	}
 
 	chainHeight := &ChainHeight{
-		Logger:             logger.With().Str("relayer_client", "chain_height").Logger(),
+		Logger:             logger,
 		errGetChainHeight:  nil,
 		lastChainHeight:    initialHeight,
 		lastBlockTimestamp: initialTimeStamp,
 	}
 
-	go chainHeight.subscribe(ctx, rpcClient, newBlockHeaderSubscription)
+	go chainHeight.subscribe(ctx, rpc, timeout, subscription)
 
 	return chainHeight, nil
 }
@@ -79,25 +74,33 @@ func (chainHeight *ChainHeight) updateChainHeight(blockHeight int64, timeStamp t
 	chainHeight.errGetChainHeight = err
 }
 
-// subscribe listens to new blocks being made
-// and updates the chain height.
+// subscribe listens to new blocks being made and updates the chain height,
+// the subscription is renewed if the websocket connection fails.
 func (chainHeight *ChainHeight) subscribe(
 	ctx context.Context,
-	eventsClient tmrpcclient.EventsClient,
-	newBlockHeaderSubscription <-chan tmctypes.ResultEvent,
+	rpc endpoint.Endpoint,
+	timeout time.Duration,
+	subscription *eventSubscription,
 ) {
 	for {
 		select {
 		case <-ctx.Done():
-			err := eventsClient.Unsubscribe(ctx, tmtypes.EventNewBlockHeader, queryEventNewBlockHeader.String())
-			if err != nil {
-				chainHeight.Logger.Err(err)
-				chainHeight.updateChainHeight(chainHeight.lastChainHeight, chainHeight.lastBlockTimestamp, err)
+			if err := subscription.Close(); err != nil {
+				chainHeight.Logger.Err(err).Msg("error closing the ChainHeight subscription")
 			}
 			chainHeight.Logger.Info().Msg("closing the ChainHeight subscription")
 			return
 
-		case resultEvent := <-newBlockHeaderSubscription:
+		case resultEvent, ok := <-subscription.Events:
+			if !ok {
+				chainHeight.Logger.Error().Msg("ChainHeight subscription closed, resubscribing")
+				if subscription = chainHeight.resubscribe(ctx, rpc, timeout); subscription == nil {
+					return
+				}
+
+				continue
+			}
+
 			eventDataNewBlockHeader, ok := resultEvent.Data.(tmtypes.EventDataNewBlockHeader)
 			if !ok {
 				chainHeight.Logger.Err(errParseEventDataNewBlockHeader)
@@ -110,6 +113,30 @@ func (chainHeight *ChainHeight) subscribe(
 	}
 }
 
+// resubscribe subscribes to EventNewBlockHeader until it succeeds, waiting the timeout
+// between attempts. It returns nil if the context is done.
+func (chainHeight *ChainHeight) resubscribe(
+	ctx context.Context,
+	rpc endpoint.Endpoint,
+	timeout time.Duration,
+) *eventSubscription {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(timeout):
+		}
+
+		subscription, err := subscribeEvents(ctx, chainHeight.Logger, rpc, queryEventNewBlockHeader.String(), timeout)
+		if err == nil {
+			return subscription
+		}
+
+		chainHeight.Logger.Err(err).Msg("error resubscribing to new blocks")
+		chainHeight.updateChainHeight(chainHeight.lastChainHeight, chainHeight.lastBlockTimestamp, err)
+	}
+}
+
 // GetChainHeight returns the last chain height available.
 func (chainHeight *ChainHeight) GetChainHeight() (int64, error) {
 	chainHeight.mtx.RLock()
@@ -2,44 +2,103 @@ package client
 
 import (
 	"context"
+	"strings"
 	"time"
 
-	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
-	tmctypes "github.com/cometbft/cometbft/rpc/core/types"
-	tmjsonclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
+	abcitypes "github.com/cometbft/cometbft/abci/types"
 	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/rs/zerolog"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
 )
 
 const (
 	wsEndpoint = "/websocket"
 )
 
-type EventSubscribe struct {
-	logger         zerolog.Logger
-	maxTickTimeout time.Duration
-	rpcAddress     []string
-	index          int
-	rpcClient      *rpchttp.HTTP
-	timeout        time.Duration
-	eventChan      <-chan tmctypes.ResultEvent
-	Tick           chan struct{}
+type (
+	EventSubscribe struct {
+		logger         zerolog.Logger
+		maxTickTimeout time.Duration
+		rpcAddress     []endpoint.Endpoint
+		index          int
+		subscription   *eventSubscription
+		timeout        time.Duration
+		Tick           chan Tick
+	}
+
+	// EventTrigger matches end block events which trigger a relayer tick.
+	// Attributes maps an attribute key to its accepted values, any value
+	// is accepted if the list is empty.
+	EventTrigger struct {
+		EventType  string
+		Attributes map[string][]string
+		Profile    string
+	}
+
+	// Tick is sent once for a new block with matching triggers, and carries the relay
+	// profiles of the matched triggers and the matched events.
+	Tick struct {
+		Profiles []string
+		Height   int64
+		Events   []abcitypes.Event
+	}
+)
+
+// Matches returns true if the event type and all the attribute filters match the event.
+func (t EventTrigger) Matches(event abcitypes.Event) bool {
+	if event.Type != t.EventType {
+		return false
+	}
+
+	for key, values := range t.Attributes {
+		found := false
+		for _, attribute := range event.Attributes {
+			if attribute.Key != key {
+				continue
+			}
+
+			if len(values) == 0 || containsValue(values, attribute.Value) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsValue checks if the attribute value is in values, typed events
+// emit json encoded attribute values so quotes are trimmed before comparing.
+func containsValue(values []string, value string) bool {
+	value = strings.Trim(value, `"`)
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
 
 func NewBlockHeightSubscription(
 	ctx context.Context,
-	rpcAddress []string,
+	rpcAddress []endpoint.Endpoint,
 	timeout time.Duration,
 	maxTickTimeout time.Duration,
-	tickEventType string,
+	triggers []EventTrigger,
 	logger zerolog.Logger,
 	skipError bool,
 	maxRetries int64,
 ) (*EventSubscribe, error) {
 	newEvent := &EventSubscribe{
-		logger: logger.With().Str("event", tickEventType).Logger(),
+		logger: logger.With().Str("module", "event_subscribe").Logger(),
 		// assuming 15-second price update
-		Tick:           make(chan struct{}, 100),
+		Tick:           make(chan Tick, 100),
 		timeout:        timeout,
 		maxTickTimeout: maxTickTimeout,
 		rpcAddress:     rpcAddress,
@@ -65,49 +124,27 @@ func NewBlockHeightSubscription(
 		}
 	}
 
-	go newEvent.subscribe(ctx, tickEventType)
+	go newEvent.subscribe(ctx, triggers)
 
 	return newEvent, nil
 }
 
-// setNewEventChan subscribes to cometbft rpc for a specific event
+// setNewEventChan subscribes to new blocks of the current cometbft rpc, over a websocket
+// connection with the transport options of the rpc endpoint.
 func (event *EventSubscribe) setNewEventChan(ctx context.Context) error {
-	event.logger.Info().Str("new rpc", event.rpcAddress[event.index]).Msg("connecting to rpc")
-	httpClient, err := tmjsonclient.DefaultHTTPClient(event.rpcAddress[event.index])
-	if err != nil {
-		return err
-	}
-
-	httpClient.Timeout = event.timeout
-
-	rpcClient, err := rpchttp.NewWithClient(
+	event.logger.Info().Str("new rpc", event.rpcAddress[event.index].Address).Msg("connecting to rpc")
+	subscription, err := subscribeEvents(
+		ctx,
+		event.logger,
 		event.rpcAddress[event.index],
-		wsEndpoint,
-		httpClient,
+		tmtypes.QueryForEvent(tmtypes.EventNewBlockHeader).String(),
+		event.timeout,
 	)
 	if err != nil {
 		return err
 	}
 
-	if !rpcClient.IsRunning() {
-		if err := rpcClient.Start(); err != nil {
-			return err
-		}
-	}
-
-	event.rpcClient = rpcClient
-	eventType := tmtypes.EventNewBlockHeader
-	queryType := tmtypes.QueryForEvent(eventType).String()
-
-	ctx, cancel := context.WithTimeout(ctx, event.timeout)
-	defer cancel()
-
-	// cometbft overrides subscriber param
-	newSubscription, err := rpcClient.Subscribe(ctx, "", queryType)
-	if err != nil {
-		return err
-	}
-	event.eventChan = newSubscription
+	event.subscription = subscription
 
 	return nil
 }
@@ -116,14 +153,13 @@ func (event *EventSubscribe) setNewEventChan(ctx context.Context) error {
 // and updates the chain height.
 func (event *EventSubscribe) subscribe(
 	ctx context.Context,
-	tickEventType string,
+	triggers []EventTrigger,
 ) {
 	current := time.Now()
 	for {
 		select {
 		case <-ctx.Done():
-			err := event.rpcClient.Unsubscribe(ctx, "", queryEventNewBlockHeader.String())
-			if err != nil {
+			if err := event.subscription.Close(); err != nil {
 				event.logger.Err(err).Msg("unsubscribing error")
 			}
 
@@ -132,7 +168,13 @@ func (event *EventSubscribe) subscribe(
 
 			return
 
-		case resultEvent := <-event.eventChan:
+		case resultEvent, ok := <-event.subscription.Events:
+			if !ok {
+				// the connection failed, the rpc is switched after the max tick timeout
+				event.subscription.Events = nil
+				continue
+			}
+
 			data, ok := resultEvent.Data.(tmtypes.EventDataNewBlockHeader)
 			if !ok {
 				event.logger.Error().Msg("no new block header")
@@ -140,20 +182,14 @@ func (event *EventSubscribe) subscribe(
 			}
 
 			events := data.ResultEndBlock.GetEvents()
-			if len(events) > 0 {
-				tick := false
-				for _, event := range events {
-					if event.Type == tickEventType {
-						tick = true
-						break
-					}
-				}
-
-				if tick {
-					current = time.Now()
-					event.logger.Info().Msg("price update event")
-					event.Tick <- struct{}{}
-				}
+			if tick, ok := matchTriggers(triggers, data.Header.Height, events); ok {
+				current = time.Now()
+				event.logger.Info().
+					Strs("profiles", tick.Profiles).
+					Int64("height", tick.Height).
+					Int("events", len(tick.Events)).
+					Msg("price update event")
+				event.Tick <- tick
 			}
 
 		default:
@@ -162,19 +198,9 @@ func (event *EventSubscribe) subscribe(
 				// reconnect to different rpc
 				event.logger.Info().Msgf("no tick since %v seconds", lapsed.Seconds())
 
-				// is rpc client is running, unsubscribe and stop
-				if event.rpcClient.IsRunning() {
-					err := event.rpcClient.UnsubscribeAll(ctx, "")
-					if err != nil {
-						event.logger.Err(err).Msg("error unsubscribing events")
-						continue
-					}
-
-					err = event.rpcClient.Stop()
-					if err != nil {
-						event.logger.Err(err).Msg("error stopping previous rpc client")
-						continue
-					}
+				// close the previous subscription
+				if err := event.subscription.Close(); err != nil {
+					event.logger.Err(err).Msg("error closing previous subscription")
 				}
 
 				// switching to alternative
@@ -196,3 +222,32 @@ func (event *EventSubscribe) switchRpc(ctx context.Context) error {
 
 	return err
 }
+
+// matchTriggers returns a single tick of the block with the profiles of all triggers matching
+// at least one event, so that profiles firing in the same block are relayed in one tx.
+// Events matched by several triggers are added once. It returns false if no trigger matches.
+func matchTriggers(triggers []EventTrigger, height int64, events []abcitypes.Event) (Tick, bool) {
+	tick := Tick{Height: height}
+	profiles := map[string]bool{}
+	for _, event := range events {
+		matched := false
+		for _, trigger := range triggers {
+			if !trigger.Matches(event) {
+				continue
+			}
+
+			if !profiles[trigger.Profile] {
+				profiles[trigger.Profile] = true
+				tick.Profiles = append(tick.Profiles, trigger.Profile)
+			}
+
+			matched = true
+		}
+
+		if matched {
+			tick.Events = append(tick.Events, event)
+		}
+	}
+
+	return tick, len(tick.Profiles) > 0
+}
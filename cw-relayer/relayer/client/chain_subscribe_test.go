@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	tmctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	tmtypes "github.com/cometbft/cometbft/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+)
+
+func newEvent(eventType string, attributes ...string) abcitypes.Event {
+	event := abcitypes.Event{Type: eventType}
+	for i := 0; i+1 < len(attributes); i += 2 {
+		event.Attributes = append(event.Attributes, abcitypes.EventAttribute{Key: attributes[i], Value: attributes[i+1]})
+	}
+
+	return event
+}
+
+func TestEventTrigger_Matches(t *testing.T) {
+	trigger := EventTrigger{
+		EventType:  "ojo.oracle.v1.EventSetFxRate",
+		Attributes: map[string][]string{"denom": {"ATOM", "OJO"}, "rate": {}},
+	}
+
+	testCases := []struct {
+		name    string
+		event   abcitypes.Event
+		matches bool
+	}{
+		{name: "matching denom", event: newEvent(trigger.EventType, "denom", "ATOM", "rate", "1.2"), matches: true},
+		{name: "quoted typed event value", event: newEvent(trigger.EventType, "denom", `"OJO"`, "rate", "1.2"), matches: true},
+		{name: "other denom", event: newEvent(trigger.EventType, "denom", "UMEE", "rate", "1.2")},
+		{name: "missing attribute", event: newEvent(trigger.EventType, "denom", "ATOM")},
+		{name: "other event type", event: newEvent("ojo.oracle.v1.EventDelegateFeedConsent", "denom", "ATOM", "rate", "1.2")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.matches, trigger.Matches(tc.event))
+		})
+	}
+
+	// triggers without attribute filters match any event of the type
+	require.True(t, EventTrigger{EventType: trigger.EventType}.Matches(newEvent(trigger.EventType)))
+}
+
+func TestMatchTriggers(t *testing.T) {
+	triggers := []EventTrigger{
+		{EventType: "rate", Attributes: map[string][]string{"denom": {"ATOM"}}, Profile: "crypto"},
+		{EventType: "rate", Attributes: map[string][]string{"denom": {"EUR"}}, Profile: "fx"},
+		{EventType: "rate", Profile: "all"},
+		{EventType: "rate", Attributes: map[string][]string{"denom": {"OJO"}}, Profile: "crypto"},
+	}
+
+	atom := newEvent("rate", "denom", "ATOM")
+	eur := newEvent("rate", "denom", "EUR")
+	ojo := newEvent("rate", "denom", "OJO")
+	other := newEvent("other")
+
+	testCases := []struct {
+		name     string
+		triggers []EventTrigger
+		events   []abcitypes.Event
+		profiles []string
+		matched  []abcitypes.Event
+	}{
+		{
+			name:     "profiles of one block share a tick",
+			triggers: triggers[:2],
+			events:   []abcitypes.Event{atom, other, eur},
+			profiles: []string{"crypto", "fx"},
+			matched:  []abcitypes.Event{atom, eur},
+		},
+		{
+			name:     "events matched by several triggers are added once",
+			triggers: triggers,
+			events:   []abcitypes.Event{atom, ojo},
+			profiles: []string{"crypto", "all"},
+			matched:  []abcitypes.Event{atom, ojo},
+		},
+		{
+			name:     "no matching trigger",
+			triggers: triggers[:2],
+			events:   []abcitypes.Event{ojo, other},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tick, ok := matchTriggers(tc.triggers, 7, tc.events)
+			require.Equal(t, len(tc.profiles) > 0, ok)
+			if !ok {
+				return
+			}
+
+			require.Equal(t, int64(7), tick.Height)
+			require.Equal(t, tc.profiles, tick.Profiles)
+			require.Equal(t, tc.matched, tick.Events)
+		})
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	query := tmtypes.QueryForEvent(tmtypes.EventNewBlockHeader).String()
+	requests := make(chan rpctypes.RPCRequest, 1)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var request rpctypes.RPCRequest
+		require.NoError(t, conn.ReadJSON(&request))
+		requests <- request
+
+		require.NoError(t, conn.WriteJSON(rpctypes.NewRPCSuccessResponse(request.ID, &tmctypes.ResultSubscribe{})))
+		require.NoError(t, conn.WriteJSON(rpctypes.NewRPCSuccessResponse(request.ID, &tmctypes.ResultEvent{
+			Query: query,
+			Data:  tmtypes.EventDataNewBlockHeader{Header: tmtypes.Header{Height: 7}},
+		})))
+	}))
+
+	// tokens are not sent over plaintext tcp, so the test server listens on a unix socket
+	socket := filepath.Join(t.TempDir(), "rpc.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	rpc := endpoint.Endpoint{Address: "unix://" + socket, Token: "token"}
+	subscription, err := subscribeEvents(context.Background(), zerolog.Nop(), rpc, query, 5*time.Second)
+	require.NoError(t, err)
+	defer subscription.Close()
+
+	request := <-requests
+	require.Equal(t, "subscribe", request.Method)
+	require.JSONEq(t, fmt.Sprintf(`{"query":%q}`, query), string(request.Params))
+
+	event := <-subscription.Events
+	require.Equal(t, int64(7), event.Data.(tmtypes.EventDataNewBlockHeader).Header.Height)
+
+	// the events are closed with the connection
+	_, ok := <-subscription.Events
+	require.False(t, ok)
+}
+
+func TestSubscribeEvents_Close(t *testing.T) {
+	query := tmtypes.QueryForEvent(tmtypes.EventNewBlockHeader).String()
+	closed := make(chan struct{})
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var request rpctypes.RPCRequest
+		require.NoError(t, conn.ReadJSON(&request))
+		require.NoError(t, conn.WriteJSON(rpctypes.NewRPCSuccessResponse(request.ID, &tmctypes.ResultSubscribe{})))
+
+		// more events than the subscription buffers
+		for i := 0; i < 150; i++ {
+			if err := conn.WriteJSON(rpctypes.NewRPCSuccessResponse(request.ID, &tmctypes.ResultEvent{
+				Query: query,
+				Data:  tmtypes.EventDataNewBlockHeader{Header: tmtypes.Header{Height: int64(i)}},
+			})); err != nil {
+				return
+			}
+		}
+
+		<-closed
+	}))
+	defer srv.Close()
+	defer close(closed)
+
+	rpc := endpoint.Endpoint{Address: "tcp://" + srv.Listener.Addr().String()}
+	subscription, err := subscribeEvents(context.Background(), zerolog.Nop(), rpc, query, 5*time.Second)
+	require.NoError(t, err)
+
+	// the reader blocks on the full buffer of an undrained subscription
+	require.Eventually(t, func() bool { return len(subscription.Events) == cap(subscription.Events) }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+
+	// the blocked event is dropped on close, and the events are closed after the buffered events
+	require.NoError(t, subscription.Close())
+	require.NoError(t, subscription.Close())
+
+	received := 0
+	for range subscription.Events {
+		received++
+	}
+
+	require.Equal(t, cap(subscription.Events), received)
+}
@@ -8,10 +8,10 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/errgroup"
-	"google.golang.org/grpc/credentials/insecure"
 
 	wasmparams "github.com/CosmWasm/wasmd/app/params"
 	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
@@ -22,12 +22,12 @@ import (
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/telemetry"
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/cosmos/cosmos-sdk/types/tx/signing"
-	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	"github.com/rs/zerolog"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
-	tmjsonclient "github.com/tendermint/tendermint/rpc/jsonrpc/client"
-	"google.golang.org/grpc"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
 )
 
 type (
@@ -38,17 +38,25 @@ type (
 		KeyringBackend    string
 		KeyringDir        string
 		KeyringPass       string
-		TMRPC             string
-		QueryRpc          string
+		TMRPC             endpoint.Endpoint
+		QueryRpc          endpoint.Endpoint
 		RPCTimeout        time.Duration
 		RelayerAddr       sdk.AccAddress
 		RelayerAddrString string
+		AddressCodec      Bech32Codec
+		Profile           ChainProfile
 		Encoding          wasmparams.EncodingConfig
 		GasPrices         string
 		GasAdjustment     float64
 		KeyringPassphrase string
 		ChainHeight       *ChainHeight
 		feeGranter        sdk.AccAddress
+		authzGranter      sdk.AccAddress
+		keyring           keyring.Keyring
+		signer            TxSigner
+
+		// if set, txs are paid by the relayer instead of the fee granter
+		selfPaidFees *atomic.Bool
 	}
 
 	passReader struct {
@@ -74,20 +82,23 @@ func NewRelayerClient(
 	keyringBackend string,
 	keyringDir string,
 	keyringPass string,
-	tmRPC string,
-	queryEndpoint string,
+	tmRPC endpoint.Endpoint,
+	queryEndpoint endpoint.Endpoint,
 	rpcTimeout time.Duration,
 	RelayerAddrString string,
 	accPrefix string,
+	profile ChainProfile,
 	gasAdjustment float64,
 	GasPrices string,
 	granter string,
+	authzGranter string,
+	remoteSigner endpoint.Endpoint,
+	multisig MultisigConfig,
 ) (RelayerClient, error) {
-	config := sdk.GetConfig()
-	config.SetBech32PrefixForAccount(accPrefix, accPrefix+sdk.PrefixPublic)
-	config.Seal()
-
-	RelayerAddr, err := sdk.AccAddressFromBech32(RelayerAddrString)
+	// addresses are encoded with the account prefix of the client instead of the global sdk config,
+	// so that clients of chains with different prefixes can be used in one process
+	addressCodec := Bech32Codec{Prefix: accPrefix}
+	RelayerAddr, err := addressCodec.Decode(RelayerAddrString)
 	if err != nil {
 		return RelayerClient{}, err
 	}
@@ -102,10 +113,31 @@ func NewRelayerClient(
 		RPCTimeout:        rpcTimeout,
 		RelayerAddr:       RelayerAddr,
 		RelayerAddrString: RelayerAddrString,
-		Encoding:          MakeEncodingConfig(),
+		AddressCodec:      addressCodec,
+		Profile:           profile,
+		Encoding:          MakeEncodingConfig(profile),
 		GasAdjustment:     gasAdjustment,
 		GasPrices:         GasPrices,
 		QueryRpc:          queryEndpoint,
+		selfPaidFees:      &atomic.Bool{},
+	}
+
+	relayerClient.keyring, err = relayerClient.openKeyring()
+	if err != nil {
+		return RelayerClient{}, err
+	}
+
+	// relay txs are signed by the multisig members if set, by the remote signer if set,
+	// or by the keyring key of the relayer address
+	if multisig.Threshold > 0 {
+		relayerClient.signer, err = relayerClient.newMultisigSigner(ctx, multisig, rpcTimeout)
+	} else {
+		var signer Signer
+		signer, err = relayerClient.newSigner(ctx, RelayerAddr, remoteSigner, rpcTimeout)
+		relayerClient.signer = NewKeySigner(addressCodec, signer)
+	}
+	if err != nil {
+		return RelayerClient{}, err
 	}
 
 	clientCtx, err := relayerClient.CreateClientContext()
@@ -114,7 +146,7 @@ func NewRelayerClient(
 	}
 
 	if len(granter) > 0 {
-		feeGranterAddr, err := sdk.AccAddressFromBech32(granter)
+		feeGranterAddr, err := addressCodec.Decode(granter)
 		if err != nil {
 			return RelayerClient{}, err
 		}
@@ -124,6 +156,17 @@ func NewRelayerClient(
 		relayerClient.feeGranter = clientCtx.GetFeeGranterAddress()
 	}
 
+	if len(authzGranter) > 0 {
+		relayerClient.authzGranter, err = addressCodec.Decode(authzGranter)
+		if err != nil {
+			return RelayerClient{}, err
+		}
+
+		if err := relayerClient.checkAuthzGrant(ctx, rpcTimeout); err != nil {
+			return RelayerClient{}, err
+		}
+	}
+
 	blockHeight, err := rpc.GetChainHeight(clientCtx)
 	if err != nil {
 		return RelayerClient{}, err
@@ -136,7 +179,8 @@ func NewRelayerClient(
 
 	chainHeight, err := NewChainHeight(
 		ctx,
-		clientCtx.Client,
+		tmRPC,
+		rpcTimeout,
 		relayerClient.logger,
 		blockHeight,
 		blockTime,
@@ -149,7 +193,8 @@ func NewRelayerClient(
 	return relayerClient, nil
 }
 
-func newPassReader(pass string) io.Reader {
+// NewPassReader returns a reader repeating the keyring passphrase for every keyring prompt.
+func NewPassReader(pass string) io.Reader {
 	return &passReader{
 		pass: pass,
 		buf:  new(bytes.Buffer),
@@ -169,31 +214,37 @@ func (r *passReader) Read(p []byte) (n int, err error) {
 
 // BroadcastTx attempts to broadcast a signed transaction. If it fails, a few re-attempts
 // will be made until the transaction succeeds or ultimately times out or fails.
-func (oc RelayerClient) BroadcastTx(timeoutDuration time.Duration, nextBlockHeight, timeoutHeight int64, msgs ...sdk.Msg) error {
+// The response of the accepted transaction is returned, it is not included in a block yet.
+// ErrFeeGrant is returned without retrying if the tx is rejected by the fee allowance.
+func (oc RelayerClient) BroadcastTx(
+	timeoutDuration time.Duration,
+	nextBlockHeight, timeoutHeight int64,
+	msgs ...sdk.Msg,
+) (*sdk.TxResponse, error) {
 	maxBlockHeight := nextBlockHeight + timeoutHeight
 	lastCheckHeight := nextBlockHeight - 1
 	start := time.Now()
 
 	clientCtx, err := oc.CreateClientContext()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	factory, err := oc.CreateTxFactory()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// re-try tx until timeout
 	for lastCheckHeight < maxBlockHeight {
 		latestBlockHeight, err := oc.ChainHeight.GetChainHeight()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if latestBlockHeight <= lastCheckHeight {
 			if time.Since(start).Seconds() >= timeoutDuration.Seconds() {
-				return fmt.Errorf("timeout duration exceeded, last check height = %v", lastCheckHeight)
+				return nil, fmt.Errorf("timeout duration exceeded, last check height = %v", lastCheckHeight)
 			}
 
 			continue
@@ -202,13 +253,23 @@ func (oc RelayerClient) BroadcastTx(timeoutDuration time.Duration, nextBlockHeig
 		// set last check height to latest block height
 		lastCheckHeight = latestBlockHeight
 
-		resp, err := BroadcastTx(oc.feeGranter, clientCtx, factory, msgs...)
+		resp, err := BroadcastTx(oc.AddressCodec.Encode(oc.FeeGranter()), clientCtx, factory, oc.signer, oc.wrapMsgs(msgs)...)
 		if resp != nil && resp.Code != 0 {
 			telemetry.IncrCounter(1, "failure", "tx", "code")
 			oc.logger.Error().Msg(resp.String())
 			err = fmt.Errorf("invalid response code from tx: %d", resp.Code)
 		}
 
+		// retrying does not help until the allowance is restored or fees are paid by the relayer
+		if err != nil && oc.FeeGranter() != nil && isFeeGrantError(resp, err) {
+			telemetry.IncrCounter(1, "failure", "tx", "fee_grant")
+			if resp != nil {
+				err = fmt.Errorf("%w: %s", err, resp.RawLog)
+			}
+
+			return nil, fmt.Errorf("%w: %s", ErrFeeGrant, err)
+		}
+
 		if err != nil {
 			var (
 				code uint32
@@ -237,28 +298,64 @@ func (oc RelayerClient) BroadcastTx(timeoutDuration time.Duration, nextBlockHeig
 			Int64("tx_height", resp.Height).
 			Msg("successfully broadcasted tx")
 
-		return nil
+		return resp, nil
 	}
 
 	telemetry.IncrCounter(1, "failure", "tx", "timeout")
-	return errors.New("broadcasting tx timed out")
+	return nil, errors.New("broadcasting tx timed out")
+}
+
+// WaitForTx polls the transaction by hash until it is included in a block, or the timeout is exceeded.
+func (oc RelayerClient) WaitForTx(ctx context.Context, txHash string, timeout time.Duration) (*sdk.TxResponse, error) {
+	clientCtx, err := oc.CreateClientContext()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("tx %s not included: %w", txHash, ctx.Err())
+		case <-ticker.C:
+		}
+
+		resp, err := authtx.QueryTx(clientCtx, txHash)
+		if err == nil {
+			return resp, nil
+		}
+	}
 }
 
 func (oc RelayerClient) BroadcastContractQuery(ctx context.Context, timeout time.Duration, queries ...SmartQuery) ([]QueryResponse, error) {
-	grpcConn, err := grpc.Dial(
-		oc.QueryRpc,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	return oc.BroadcastContractQueryAtHeight(ctx, timeout, 0, queries...)
+}
 
+// BroadcastContractQueryAtHeight queries the contract state at the given height,
+// or at the latest height if height is not positive.
+func (oc RelayerClient) BroadcastContractQueryAtHeight(
+	ctx context.Context,
+	timeout time.Duration,
+	height int64,
+	queries ...SmartQuery,
+) ([]QueryResponse, error) {
+	grpcConn, err := oc.QueryRpc.Dial()
 	if err != nil {
 		return nil, err
 	}
 
 	defer grpcConn.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	ctx = WithQueryHeight(ctx, height)
+
 	g, _ := errgroup.WithContext(ctx)
 
 	queryClient := wasmtypes.NewQueryClient(grpcConn)
@@ -286,34 +383,126 @@ func (oc RelayerClient) BroadcastContractQuery(ctx context.Context, timeout time
 	return responses, err
 }
 
+// Balance queries the bank balance of the address in the denom.
+func (oc RelayerClient) Balance(ctx context.Context, timeout time.Duration, address sdk.AccAddress, denom string) (sdk.Coin, error) {
+	grpcConn, err := oc.QueryRpc.Dial()
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+
+	defer grpcConn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := banktypes.NewQueryClient(grpcConn).Balance(ctx, &banktypes.QueryBalanceRequest{
+		Address: oc.AddressCodec.Encode(address),
+		Denom:   denom,
+	})
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+
+	if resp.Balance == nil {
+		return sdk.NewCoin(denom, sdk.ZeroInt()), nil
+	}
+
+	return *resp.Balance, nil
+}
+
+// Send broadcasts a bank send of the amount from the funder to the relayer account.
+// The funder key must be in the relayer keyring, and the funder pays its own fees.
+func (oc RelayerClient) Send(funder sdk.AccAddress, amount sdk.Coins) (*sdk.TxResponse, error) {
+	signer, err := NewKeyringSigner(oc.keyring, funder)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCtx, err := oc.createClientContext(funder)
+	if err != nil {
+		return nil, err
+	}
+
+	msgSend := &banktypes.MsgSend{
+		FromAddress: oc.AddressCodec.Encode(funder),
+		ToAddress:   oc.RelayerAddrString,
+		Amount:      amount,
+	}
+
+	factory := oc.createTxFactory(clientCtx)
+	resp, err := BroadcastTx("", clientCtx, factory, NewKeySigner(oc.AddressCodec, signer), msgSend)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Code != 0 {
+		return resp, fmt.Errorf("invalid response code from tx: %d", resp.Code)
+	}
+
+	return resp, nil
+}
+
 // CreateClientContext creates an SDK client Context instance used for transaction
 // generation, signing and broadcasting.
 func (oc RelayerClient) CreateClientContext() (client.Context, error) {
-	var keyringInput io.Reader
-	if len(oc.KeyringPass) > 0 {
-		keyringInput = newPassReader(oc.KeyringPass)
-	} else {
-		keyringInput = os.Stdin
+	return oc.createClientContext(oc.RelayerAddr)
+}
+
+// newSigner returns the remote signer of the address if the remote address is set,
+// or the keyring signer of the address otherwise.
+func (oc RelayerClient) newSigner(
+	ctx context.Context,
+	address sdk.AccAddress,
+	remote endpoint.Endpoint,
+	timeout time.Duration,
+) (Signer, error) {
+	if len(remote.Address) > 0 {
+		return NewRemoteSigner(ctx, remote, timeout, oc.AddressCodec, address)
 	}
 
-	kr, err := keyring.New("relayer", oc.KeyringBackend, oc.KeyringDir, keyringInput, oc.Encoding.Marshaler)
-	if err != nil {
-		return client.Context{}, err
+	return NewKeyringSigner(oc.keyring, address)
+}
+
+// newMultisigSigner returns the signer of the multisig relayer account.
+func (oc RelayerClient) newMultisigSigner(ctx context.Context, config MultisigConfig, timeout time.Duration) (*MultisigSigner, error) {
+	members := make([]Signer, len(config.Members))
+	for i, member := range config.Members {
+		address, err := oc.AddressCodec.Decode(member.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		members[i], err = oc.newSigner(ctx, address, member.Remote, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("multisig member %s: %w", member.Address, err)
+		}
 	}
 
-	httpClient, err := tmjsonclient.DefaultHTTPClient(oc.TMRPC)
-	if err != nil {
-		return client.Context{}, err
+	return NewMultisigSigner(oc.logger, oc.AddressCodec, oc.RelayerAddr, config.Threshold, members)
+}
+
+// openKeyring opens the relayer keyring, the keyring passphrase is read from stdin if not set.
+func (oc RelayerClient) openKeyring() (keyring.Keyring, error) {
+	var keyringInput io.Reader
+	if len(oc.KeyringPass) > 0 {
+		keyringInput = NewPassReader(oc.KeyringPass)
+	} else {
+		keyringInput = os.Stdin
 	}
 
-	httpClient.Timeout = oc.RPCTimeout
+	return keyring.New("relayer", oc.KeyringBackend, oc.KeyringDir, keyringInput, oc.Encoding.Marshaler, oc.Profile.KeyringOptions()...)
+}
 
-	tmRPC, err := rpchttp.NewWithClient(oc.TMRPC, "/websocket", httpClient)
+// createClientContext creates an SDK client Context instance for txs sent from the address,
+// txs are signed by a Signer instead of the client keyring.
+func (oc RelayerClient) createClientContext(from sdk.AccAddress) (client.Context, error) {
+	httpClient, err := oc.TMRPC.HTTPClient(oc.RPCTimeout)
 	if err != nil {
 		return client.Context{}, err
 	}
 
-	keyInfo, err := kr.KeyByAddress(oc.RelayerAddr)
+	// only the http client is used, new blocks are subscribed by the ChainHeight websocket subscription
+	tmRPC, err := rpchttp.NewWithClient(oc.TMRPC.Address, "/websocket", httpClient)
 	if err != nil {
 		return client.Context{}, err
 	}
@@ -324,16 +513,15 @@ func (oc RelayerClient) CreateClientContext() (client.Context, error) {
 		Output:            os.Stderr,
 		BroadcastMode:     flags.BroadcastSync,
 		TxConfig:          oc.Encoding.TxConfig,
-		AccountRetriever:  authtypes.AccountRetriever{},
+		AccountRetriever:  oc.Profile.accountRetriever(oc.AddressCodec),
 		Codec:             oc.Encoding.Marshaler,
 		LegacyAmino:       oc.Encoding.Amino,
 		Input:             os.Stdin,
-		NodeURI:           oc.TMRPC,
+		NodeURI:           oc.TMRPC.Address,
 		Client:            tmRPC,
-		Keyring:           kr,
-		FromAddress:       oc.RelayerAddr,
-		FromName:          keyInfo.Name,
-		From:              keyInfo.Name,
+		Keyring:           oc.keyring,
+		FromAddress:       from,
+		From:              oc.AddressCodec.Encode(from),
 		OutputFormat:      "json",
 		UseLedger:         false,
 		Simulate:          false,
@@ -353,18 +541,20 @@ func (oc RelayerClient) CreateTxFactory() (tx.Factory, error) {
 		return tx.Factory{}, err
 	}
 
-	txFactory := tx.Factory{}.
+	return oc.createTxFactory(clientCtx), nil
+}
+
+// createTxFactory creates an SDK Factory instance for the client context. The fee granter is set
+// by BroadcastTx, as the factory encodes it with the global sdk config.
+func (oc RelayerClient) createTxFactory(clientCtx client.Context) tx.Factory {
+	return tx.Factory{}.
 		WithAccountRetriever(clientCtx.AccountRetriever).
 		WithChainID(oc.ChainID).
 		WithTxConfig(clientCtx.TxConfig).
 		WithGasAdjustment(oc.GasAdjustment).
 		WithGasPrices(oc.GasPrices).
-		WithKeybase(clientCtx.Keyring).
-		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
-		WithSimulateAndExecute(true).
-		WithFeeGranter(oc.feeGranter)
-
-	return txFactory, nil
+		WithSignMode(oc.Profile.SignMode).
+		WithSimulateAndExecute(true)
 }
 
 func GetChainTimestamp(clientCtx client.Context) (time.Time, error) {
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+)
+
+// errGrantNotFound is the error message of the feegrant module if the grant does not exist.
+const errGrantNotFound = "fee-grant not found"
+
+var (
+	// ErrNoFeeAllowance is returned if the fee granter has not granted an allowance to the relayer.
+	ErrNoFeeAllowance = errors.New("fee allowance not found")
+
+	// ErrFeeGrant is returned by BroadcastTx if the tx is rejected by the fee allowance.
+	ErrFeeGrant = errors.New("tx rejected by the fee allowance")
+
+	// feeGrantErrors are the errors of txs rejected by the fee allowance of the fee granter.
+	feeGrantErrors = []string{
+		errGrantNotFound,
+		feegrant.ErrFeeLimitExceeded.Error(),
+		feegrant.ErrFeeLimitExpired.Error(),
+		feegrant.ErrNoAllowance.Error(),
+		feegrant.ErrMessageNotAllowed.Error(),
+	}
+)
+
+// FeeAllowance defines the remaining spend limit and the expiration of a fee allowance.
+// SpendLimit is nil if the allowance is unlimited, and Expiration is nil if it does not expire.
+type FeeAllowance struct {
+	SpendLimit sdk.Coins
+	Expiration *time.Time
+}
+
+// HasFeeGranter returns true if a fee granter is configured, even if fees are currently self-paid.
+func (oc RelayerClient) HasFeeGranter() bool {
+	return !oc.feeGranter.Empty()
+}
+
+// FeeGranter returns the fee granter set on txs, it is nil if fees are paid by the relayer.
+func (oc RelayerClient) FeeGranter() sdk.AccAddress {
+	if oc.selfPaidFees != nil && oc.selfPaidFees.Load() {
+		return nil
+	}
+
+	return oc.feeGranter
+}
+
+// SetSelfPaidFees sets whether txs are paid by the relayer account instead of the fee granter.
+func (oc RelayerClient) SetSelfPaidFees(selfPaid bool) {
+	if oc.selfPaidFees != nil {
+		oc.selfPaidFees.Store(selfPaid)
+	}
+}
+
+// isFeeGrantError returns true if the tx was rejected by the fee allowance, either on simulation
+// or by the check tx response.
+func isFeeGrantError(resp *sdk.TxResponse, err error) bool {
+	if resp != nil && resp.Codespace == feegrant.DefaultCodespace {
+		return true
+	}
+
+	if err == nil {
+		return false
+	}
+
+	for _, feeGrantErr := range feeGrantErrors {
+		if strings.Contains(err.Error(), feeGrantErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FeeAllowance queries the allowance granted by the fee granter to the relayer.
+// ErrNoFeeAllowance is returned if the grant does not exist.
+func (oc RelayerClient) FeeAllowance(ctx context.Context, timeout time.Duration) (FeeAllowance, error) {
+	if !oc.HasFeeGranter() {
+		return FeeAllowance{}, fmt.Errorf("fee granter not set")
+	}
+
+	grpcConn, err := oc.QueryRpc.Dial()
+	if err != nil {
+		return FeeAllowance{}, err
+	}
+
+	defer grpcConn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := feegrant.NewQueryClient(grpcConn).Allowance(ctx, &feegrant.QueryAllowanceRequest{
+		Granter: oc.AddressCodec.Encode(oc.feeGranter),
+		Grantee: oc.RelayerAddrString,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), errGrantNotFound) {
+			return FeeAllowance{}, ErrNoFeeAllowance
+		}
+
+		return FeeAllowance{}, err
+	}
+
+	if resp.Allowance == nil {
+		return FeeAllowance{}, ErrNoFeeAllowance
+	}
+
+	var allowance feegrant.FeeAllowanceI
+	if err := oc.Encoding.InterfaceRegistry.UnpackAny(resp.Allowance.Allowance, &allowance); err != nil {
+		return FeeAllowance{}, err
+	}
+
+	return feeAllowance(allowance)
+}
+
+// feeAllowance returns the spend limit and expiration of the allowance. The spend limit of a
+// periodic allowance is the amount which can still be spent in the current period.
+func feeAllowance(allowance feegrant.FeeAllowanceI) (FeeAllowance, error) {
+	switch allowance := allowance.(type) {
+	case *feegrant.BasicAllowance:
+		return FeeAllowance{SpendLimit: allowance.SpendLimit, Expiration: allowance.Expiration}, nil
+
+	case *feegrant.PeriodicAllowance:
+		return FeeAllowance{SpendLimit: allowance.PeriodCanSpend, Expiration: allowance.Basic.Expiration}, nil
+
+	case *feegrant.AllowedMsgAllowance:
+		inner, err := allowance.GetAllowance()
+		if err != nil {
+			return FeeAllowance{}, err
+		}
+
+		return feeAllowance(inner)
+
+	default:
+		return FeeAllowance{}, fmt.Errorf("unsupported fee allowance %T", allowance)
+	}
+}
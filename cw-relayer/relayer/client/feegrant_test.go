@@ -0,0 +1,54 @@
+package client
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeAllowance(t *testing.T) {
+	expiration := time.Unix(1700000000, 0)
+	basic := feegrant.BasicAllowance{
+		SpendLimit: sdk.NewCoins(sdk.NewInt64Coin("stake", 100)),
+		Expiration: &expiration,
+	}
+
+	allowance, err := feeAllowance(&basic)
+	require.NoError(t, err)
+	require.Equal(t, basic.SpendLimit, allowance.SpendLimit)
+	require.Equal(t, expiration, *allowance.Expiration)
+
+	// the spend limit of a periodic allowance is the amount left in the period
+	periodic := feegrant.PeriodicAllowance{
+		Basic:          basic,
+		PeriodCanSpend: sdk.NewCoins(sdk.NewInt64Coin("stake", 10)),
+	}
+
+	allowed, err := feegrant.NewAllowedMsgAllowance(&periodic, []string{"/cosmwasm.wasm.v1.MsgExecuteContract"})
+	require.NoError(t, err)
+
+	allowance, err = feeAllowance(allowed)
+	require.NoError(t, err)
+	require.Equal(t, periodic.PeriodCanSpend, allowance.SpendLimit)
+	require.Equal(t, expiration, *allowance.Expiration)
+
+	client := RelayerClient{feeGranter: sdk.AccAddress("granter"), selfPaidFees: &atomic.Bool{}}
+	require.Equal(t, sdk.AccAddress("granter"), client.FeeGranter())
+
+	client.SetSelfPaidFees(true)
+	require.Nil(t, client.FeeGranter())
+	require.True(t, client.HasFeeGranter())
+}
+
+func TestIsFeeGrantError(t *testing.T) {
+	require.True(t, isFeeGrantError(&sdk.TxResponse{Code: 2, Codespace: feegrant.DefaultCodespace}, errors.New("invalid response code from tx: 2")))
+	require.True(t, isFeeGrantError(nil, errors.New("rpc error: wasm1 does not not allow to pay fees for wasm2: fee limit exceeded")))
+	require.True(t, isFeeGrantError(nil, errors.New("fee-grant not found: not found")))
+	require.False(t, isFeeGrantError(&sdk.TxResponse{Code: 5, Codespace: "sdk"}, errors.New("insufficient funds")))
+	require.False(t, isFeeGrantError(nil, nil))
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/rs/zerolog"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+)
+
+type (
+	// MultisigConfig defines the k-of-n multisig relayer account, txs are signed by Threshold members.
+	// The multisig is disabled if Threshold is zero.
+	MultisigConfig struct {
+		Threshold int
+		Members   []MultisigMember
+	}
+
+	// MultisigMember defines a member key of the multisig, signing with the keyring key
+	// of the address or with the remote signer if Remote is set.
+	MultisigMember struct {
+		Address string
+		Remote  endpoint.Endpoint
+	}
+
+	// MultisigSigner signs txs of a multisig account by collecting signatures from its members.
+	MultisigSigner struct {
+		logger  zerolog.Logger
+		codec   Bech32Codec
+		pubKey  *kmultisig.LegacyAminoPubKey
+		members []Signer
+	}
+)
+
+// NewMultisigSigner returns a signer of the threshold multisig of the member keys. The members are used in
+// the given order, or sorted by address as by the keys add --multisig command, whichever matches the address.
+func NewMultisigSigner(
+	logger zerolog.Logger,
+	codec Bech32Codec,
+	address sdk.AccAddress,
+	threshold int,
+	members []Signer,
+) (*MultisigSigner, error) {
+	if threshold <= 0 || threshold > len(members) {
+		return nil, fmt.Errorf("invalid multisig threshold %d of %d members", threshold, len(members))
+	}
+
+	sorted := append([]Signer{}, members...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Address(), sorted[j].Address()) < 0
+	})
+
+	for _, ordered := range [][]Signer{members, sorted} {
+		pubKeys := make([]cryptotypes.PubKey, len(ordered))
+		for i, member := range ordered {
+			pubKeys[i] = member.PubKey()
+		}
+
+		pubKey := kmultisig.NewLegacyAminoPubKey(threshold, pubKeys)
+		if bytes.Equal(pubKey.Address(), address) {
+			return &MultisigSigner{
+				logger:  logger.With().Str("module", "multisig").Logger(),
+				codec:   codec,
+				pubKey:  pubKey,
+				members: ordered,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("multisig of the members does not match address %s", codec.Encode(address))
+}
+
+// Address returns the multisig address.
+func (s *MultisigSigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.pubKey.Address())
+}
+
+// SignTx signs the tx with the threshold number of members, members which fail to sign are skipped.
+// Members sign the legacy amino json sign bytes, as direct sign bytes are not supported by multisigs.
+func (s *MultisigSigner) SignTx(ctx context.Context, txConfig client.TxConfig, txf tx.Factory, txBuilder client.TxBuilder) error {
+	signMode := signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+	signerData := authsigning.SignerData{
+		Address:       s.codec.Encode(s.Address()),
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+		PubKey:        s.pubKey,
+	}
+
+	sig := signing.SignatureV2{
+		PubKey:   s.pubKey,
+		Data:     multisig.NewMultisig(len(s.members)),
+		Sequence: txf.Sequence(),
+	}
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return err
+	}
+
+	signBytes, err := txConfig.SignModeHandler().GetSignBytes(signMode, signerData, txBuilder.GetTx())
+	if err != nil {
+		return err
+	}
+
+	pubKeys := s.pubKey.GetPubKeys()
+	multisigData := multisig.NewMultisig(len(s.members))
+
+	signatures := 0
+	for _, member := range s.members {
+		signature, err := member.Sign(ctx, signBytes)
+		if err != nil {
+			s.logger.Err(err).Str("member", s.codec.Encode(member.Address())).Msg("multisig member failed to sign")
+			continue
+		}
+
+		memberSig := &signing.SingleSignatureData{SignMode: signMode, Signature: signature}
+		if err := multisig.AddSignatureFromPubKey(multisigData, memberSig, member.PubKey(), pubKeys); err != nil {
+			return err
+		}
+
+		signatures++
+		if signatures == int(s.pubKey.Threshold) {
+			sig.Data = multisigData
+			return txBuilder.SetSignatures(sig)
+		}
+	}
+
+	return fmt.Errorf("collected %d of %d multisig signatures", signatures, s.pubKey.Threshold)
+}
+
+// simSignature returns the signature of a simulated tx, with empty signatures of the first threshold
+// members, so that the verification gas of the multisig is consumed.
+func (s *MultisigSigner) simSignature(sequence uint64) signing.SignatureV2 {
+	data := multisig.NewMultisig(len(s.members))
+	for i := 0; i < int(s.pubKey.Threshold); i++ {
+		data.BitArray.SetIndex(i, true)
+		data.Signatures = append(data.Signatures, &signing.SingleSignatureData{SignMode: signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON})
+	}
+
+	return signing.SignatureV2{PubKey: s.pubKey, Data: data, Sequence: sequence}
+}
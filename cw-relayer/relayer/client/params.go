@@ -6,11 +6,21 @@ import (
 	"github.com/cosmos/cosmos-sdk/std"
 )
 
-func MakeEncodingConfig() wasmparams.EncodingConfig {
+// MakeEncodingConfig returns the encoding config of the wasmd modules and the interfaces of the chain profile.
+func MakeEncodingConfig(profile ChainProfile) wasmparams.EncodingConfig {
 	encodingConfig := wasmparams.MakeEncodingConfig()
 	std.RegisterLegacyAminoCodec(encodingConfig.Amino)
 	std.RegisterInterfaces(encodingConfig.InterfaceRegistry)
 	app.ModuleBasics.RegisterLegacyAminoCodec(encodingConfig.Amino)
 	app.ModuleBasics.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+
+	if profile.RegisterLegacyAminoCodec != nil {
+		profile.RegisterLegacyAminoCodec(encodingConfig.Amino)
+	}
+
+	if profile.RegisterInterfaces != nil {
+		profile.RegisterInterfaces(encodingConfig.InterfaceRegistry)
+	}
+
 	return encodingConfig
 }
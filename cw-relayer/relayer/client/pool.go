@@ -0,0 +1,387 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/ojo-network/ojo/x/oracle/types"
+	"github.com/rs/zerolog"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+)
+
+const (
+	// smoothing factor of the latency and error rate moving averages
+	healthAlpha = 0.2
+	// score penalty of an endpoint that fails every query
+	errorWeight = 10.0
+	// the error rate halves every half life, so that unused and demoted endpoints recover
+	errorHalfLife = time.Minute
+	// score penalty for each block an endpoint lags behind the freshest endpoint
+	lagWeight = 1.0
+	// lag is capped so that a lagging endpoint can still be preferred over a failing one
+	maxLag     = 10
+	maxBackoff = 5 * time.Second
+	// max number of endpoint switches kept to detect switch storms
+	maxSwitches = 1024
+)
+
+var errNoEndpoints = errors.New("no query endpoints")
+
+type (
+	// EndpointPool keeps persistent price sources for the ojo query endpoints
+	// and routes queries to the healthiest and freshest endpoint.
+	EndpointPool struct {
+		logger     zerolog.Logger
+		mtx        sync.RWMutex
+		endpoints  []*poolEndpoint
+		maxRetries int64
+		backoff    time.Duration
+
+		// time of the recent switches to another endpoint after a failed query
+		switches []time.Time
+	}
+
+	// poolEndpoint tracks the health of a single query endpoint.
+	poolEndpoint struct {
+		address   string
+		source    PriceSource
+		fallback  bool
+		latency   time.Duration
+		errorRate float64
+		// time of the last error rate update
+		updated time.Time
+		height  int64
+	}
+
+	// DataError is returned by query functions for a valid response lacking the expected data,
+	// e.g. empty medians. Data errors are returned without retrying or penalising the endpoint.
+	DataError struct {
+		msg string
+	}
+
+	// observedSource records the latency and height of every query made on the endpoint.
+	observedSource struct {
+		PriceSource
+		pool     *EndpointPool
+		endpoint *poolEndpoint
+	}
+)
+
+// NewEndpointPool creates a price source for each of the given endpoints, connections are
+// established lazily and kept open until the pool is closed. Fallback endpoints are only used
+// when the primary endpoints fail. A failed query is retried at most maxRetries times on the
+// next healthiest endpoint, waiting an exponential backoff between attempts.
+func NewEndpointPool(
+	logger zerolog.Logger,
+	endpoints []endpoint.Endpoint,
+	fallbacks []endpoint.Endpoint,
+	maxRetries int64,
+	backoff time.Duration,
+) (*EndpointPool, error) {
+	if len(endpoints) == 0 {
+		return nil, errNoEndpoints
+	}
+
+	pool := &EndpointPool{
+		logger:     logger.With().Str("module", "endpoint_pool").Logger(),
+		maxRetries: maxRetries,
+		backoff:    backoff,
+	}
+
+	for i, queryEndpoint := range append(endpoints, fallbacks...) {
+		source, err := NewPriceSource(queryEndpoint)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+
+		e := &poolEndpoint{address: queryEndpoint.Address, fallback: i >= len(endpoints)}
+		e.source = observedSource{PriceSource: source, pool: pool, endpoint: e}
+		pool.endpoints = append(pool.endpoints, e)
+	}
+
+	return pool, nil
+}
+
+// NewDataError returns a data error with the given message.
+func NewDataError(msg string) error {
+	return &DataError{msg: msg}
+}
+
+func (e *DataError) Error() string {
+	return e.msg
+}
+
+// IsDataError returns true if the error is a data error.
+func IsDataError(err error) bool {
+	var dataErr *DataError
+	return errors.As(err, &dataErr)
+}
+
+// Do calls fn with the address and price source of the healthiest endpoint. If fn returns an error,
+// the endpoint is penalised and fn is retried on the next healthiest primary endpoint, at most
+// maxRetries times. The fallback endpoints are then tried once each, regardless of maxRetries.
+// Data errors are returned as is. The error of the last attempt is returned if all attempts fail.
+func (p *EndpointPool) Do(ctx context.Context, fn func(address string, source PriceSource) error) error {
+	tried := map[*poolEndpoint]bool{}
+	backoff := p.backoff
+
+	var err error
+	for attempt := int64(0); ; attempt++ {
+		e := p.next(tried, attempt > p.maxRetries)
+		if e == nil {
+			return err
+		}
+
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+
+			p.recordSwitch()
+		}
+
+		tried[e] = true
+
+		err = fn(e.address, e.source)
+		p.record(e, err)
+		if err == nil || IsDataError(err) {
+			return err
+		}
+
+		telemetry.IncrCounter(1, "failure", "query", "endpoint")
+		p.logger.Debug().Err(err).Str("endpoint", e.address).Int64("attempt", attempt).Msg("query failed")
+	}
+}
+
+// Quorum calls fn concurrently on the size healthiest primary endpoints and returns their addresses
+// in ranked order. Endpoints whose call failed are penalised and logged, data errors are only logged.
+// Fallback endpoints never count towards the quorum.
+func (p *EndpointPool) Quorum(
+	ctx context.Context,
+	size int,
+	fn func(address string, source PriceSource) error,
+) ([]string, error) {
+	ranked := p.ranked(false)
+	if len(ranked) < size {
+		return nil, fmt.Errorf("quorum size %d exceeds %d endpoints", size, len(ranked))
+	}
+
+	var wg sync.WaitGroup
+	addresses := make([]string, size)
+	for i, e := range ranked[:size] {
+		addresses[i] = e.address
+
+		wg.Add(1)
+		go func(e *poolEndpoint) {
+			defer wg.Done()
+
+			err := fn(e.address, e.source)
+			p.record(e, err)
+			if err != nil {
+				if !IsDataError(err) {
+					telemetry.IncrCounter(1, "failure", "query", "endpoint")
+				}
+
+				p.logger.Error().Err(err).Str("endpoint", e.address).Msg("quorum query failed")
+			}
+		}(e)
+	}
+
+	wg.Wait()
+	return addresses, ctx.Err()
+}
+
+// Switches returns the number of switches to another endpoint after a failed query within the window.
+func (p *EndpointPool) Switches(window time.Duration) int {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	since := time.Now().Add(-window)
+	count := 0
+	for _, t := range p.switches {
+		if t.After(since) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// recordSwitch records a switch to another endpoint.
+func (p *EndpointPool) recordSwitch() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.switches = append(p.switches, time.Now())
+	if len(p.switches) > maxSwitches {
+		p.switches = p.switches[len(p.switches)-maxSwitches:]
+	}
+}
+
+// Demote sets the error rate of the endpoint to the maximum, ranking it behind all the
+// endpoints which have not failed recently until the error rate has decayed.
+func (p *EndpointPool) Demote(address string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for _, e := range p.endpoints {
+		if e.address == address {
+			e.errorRate = 1
+			e.updated = time.Now()
+		}
+	}
+}
+
+// Close closes the price sources of all endpoints.
+func (p *EndpointPool) Close() {
+	for _, e := range p.endpoints {
+		if err := e.source.Close(); err != nil {
+			p.logger.Err(err).Str("endpoint", e.address).Msg("error closing price source")
+		}
+	}
+}
+
+// next returns the healthiest primary endpoint not yet tried, or the healthiest primary endpoint
+// if all of them have been tried. If fallback is set, it returns the healthiest fallback endpoint
+// not yet tried, or nil if all of them have been tried.
+func (p *EndpointPool) next(tried map[*poolEndpoint]bool, fallback bool) *poolEndpoint {
+	ranked := p.ranked(fallback)
+	for _, e := range ranked {
+		if !tried[e] {
+			return e
+		}
+	}
+
+	if fallback || len(ranked) == 0 {
+		return nil
+	}
+
+	return ranked[0]
+}
+
+// ranked returns the primary or the fallback endpoints ordered by score,
+// endpoints with equal scores keep the configured order.
+func (p *EndpointPool) ranked(fallback bool) []*poolEndpoint {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	var maxHeight int64
+	for _, e := range p.endpoints {
+		if e.height > maxHeight {
+			maxHeight = e.height
+		}
+	}
+
+	now := time.Now()
+	scores := make(map[*poolEndpoint]float64, len(p.endpoints))
+	var ranked []*poolEndpoint
+	for _, e := range p.endpoints {
+		if e.fallback == fallback {
+			scores[e] = e.score(maxHeight, now)
+			ranked = append(ranked, e)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] < scores[ranked[j]]
+	})
+
+	return ranked
+}
+
+// record updates the error rate of the endpoint, data errors are recorded as successful queries.
+func (p *EndpointPool) record(e *poolEndpoint, err error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	var failed float64
+	if err != nil && !IsDataError(err) {
+		failed = 1
+	}
+
+	now := time.Now()
+	e.errorRate = e.decayedErrorRate(now)*(1-healthAlpha) + failed*healthAlpha
+	e.updated = now
+}
+
+// observe updates the latency and the latest height reported by the endpoint. The height served
+// by a query pinned to a height is not the latest height of the endpoint, so only the heights of
+// unpinned queries are recorded.
+func (p *EndpointPool) observe(e *poolEndpoint, latency time.Duration, pinnedHeight, height int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(float64(e.latency)*(1-healthAlpha) + float64(latency)*healthAlpha)
+	}
+
+	if pinnedHeight <= 0 && height > e.height {
+		e.height = height
+	}
+}
+
+func (s observedSource) ExchangeRates(ctx context.Context, height int64) (sdk.DecCoins, int64, error) {
+	start := time.Now()
+	rates, queryHeight, err := s.PriceSource.ExchangeRates(ctx, height)
+	s.pool.observe(s.endpoint, time.Since(start), height, queryHeight)
+
+	return rates, queryHeight, err
+}
+
+func (s observedSource) Medians(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error) {
+	start := time.Now()
+	medians, queryHeight, err := s.PriceSource.Medians(ctx, height)
+	s.pool.observe(s.endpoint, time.Since(start), height, queryHeight)
+
+	return medians, queryHeight, err
+}
+
+func (s observedSource) MedianDeviations(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error) {
+	start := time.Now()
+	deviations, queryHeight, err := s.PriceSource.MedianDeviations(ctx, height)
+	s.pool.observe(s.endpoint, time.Since(start), height, queryHeight)
+
+	return deviations, queryHeight, err
+}
+
+// score returns the health score of the endpoint, lower is better.
+// Endpoints which have not reported a height yet are not penalised for lag.
+func (e *poolEndpoint) score(maxHeight int64, now time.Time) float64 {
+	var lag int64
+	if e.height > 0 {
+		lag = maxHeight - e.height
+	}
+
+	if lag > maxLag {
+		lag = maxLag
+	}
+
+	return e.decayedErrorRate(now)*errorWeight + float64(lag)*lagWeight + e.latency.Seconds()
+}
+
+// decayedErrorRate returns the error rate decayed by the time since the last update.
+func (e *poolEndpoint) decayedErrorRate(now time.Time) float64 {
+	if e.updated.IsZero() || !now.After(e.updated) {
+		return e.errorRate
+	}
+
+	return e.errorRate * math.Pow(0.5, float64(now.Sub(e.updated))/float64(errorHalfLife))
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+)
+
+func TestEndpointPool_Do(t *testing.T) {
+	pool, err := NewEndpointPool(zerolog.Nop(), []endpoint.Endpoint{
+		endpoint.New("localhost:1"),
+		endpoint.New("localhost:2"),
+	}, []endpoint.Endpoint{
+		endpoint.New("localhost:3"),
+	}, 1, time.Millisecond)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// first endpoint fails, the query is retried on the second endpoint
+	var used []string
+	err = pool.Do(context.Background(), func(address string, _ PriceSource) error {
+		used = append(used, address)
+		if address == "localhost:1" {
+			return errors.New("query failed")
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"localhost:1", "localhost:2"}, used)
+
+	// the failing endpoint is ranked behind the healthy endpoint
+	require.Equal(t, []*poolEndpoint{pool.endpoints[1], pool.endpoints[0]}, pool.ranked(false))
+	require.Equal(t, []*poolEndpoint{pool.endpoints[2]}, pool.ranked(true))
+
+	// retries of the primary endpoints are bounded, the fallback endpoint is tried after them
+	used = nil
+	err = pool.Do(context.Background(), func(address string, _ PriceSource) error {
+		used = append(used, address)
+		return errors.New("query failed")
+	})
+	require.Error(t, err)
+	require.Equal(t, []string{"localhost:2", "localhost:1", "localhost:3"}, used)
+
+	// each retry switched to another endpoint
+	require.Equal(t, 3, pool.Switches(time.Minute))
+}
+
+func TestEndpointPool_Fallback(t *testing.T) {
+	pool, err := NewEndpointPool(zerolog.Nop(), []endpoint.Endpoint{
+		endpoint.New("localhost:1"),
+		endpoint.New("localhost:2"),
+	}, []endpoint.Endpoint{
+		endpoint.New("localhost:3"),
+	}, 0, time.Millisecond)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// the fallback endpoint is reached without retries
+	var used []string
+	err = pool.Do(context.Background(), func(address string, _ PriceSource) error {
+		used = append(used, address)
+		if address != "localhost:3" {
+			return errors.New("query failed")
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"localhost:1", "localhost:3"}, used)
+
+	// fallback endpoints do not count towards the quorum
+	_, err = pool.Quorum(context.Background(), 3, func(string, PriceSource) error { return nil })
+	require.Error(t, err)
+
+	addresses, err := pool.Quorum(context.Background(), 2, func(string, PriceSource) error { return nil })
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"localhost:1", "localhost:2"}, addresses)
+}
+
+func TestEndpoint_Score(t *testing.T) {
+	fresh := &poolEndpoint{height: 100, latency: 100 * time.Millisecond}
+	lagging := &poolEndpoint{height: 95, latency: 10 * time.Millisecond}
+	unknown := &poolEndpoint{}
+	failing := &poolEndpoint{height: 100, errorRate: 1}
+
+	now := time.Now()
+	require.Less(t, fresh.score(100, now), lagging.score(100, now))
+	require.Equal(t, 0.0, unknown.score(100, now))
+	require.Less(t, lagging.score(100, now), failing.score(100, now))
+
+	// the error rate of an unused endpoint decays over time
+	failing.updated = now.Add(-errorHalfLife)
+	require.InDelta(t, 0.5, failing.decayedErrorRate(now), 1e-9)
+	failing.updated = now.Add(-10 * errorHalfLife)
+	require.Less(t, failing.score(100, now), lagging.score(100, now))
+}
+
+func TestEndpointPool_Observe(t *testing.T) {
+	pool, err := NewEndpointPool(zerolog.Nop(), []endpoint.Endpoint{endpoint.New("localhost:1")}, nil, 0, 0)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	e := pool.endpoints[0]
+	pool.observe(e, time.Millisecond, 0, 100)
+	require.Equal(t, int64(100), e.height)
+
+	// heights served by pinned queries are not the latest height of the endpoint
+	pool.observe(e, time.Millisecond, 120, 120)
+	require.Equal(t, int64(100), e.height)
+
+	pool.observe(e, time.Millisecond, 0, 101)
+	require.Equal(t, int64(101), e.height)
+}
+
+func TestEndpointPool_Demote(t *testing.T) {
+	pool, err := NewEndpointPool(zerolog.Nop(), []endpoint.Endpoint{
+		endpoint.New("localhost:1"),
+		endpoint.New("localhost:2"),
+	}, nil, 1, time.Millisecond)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	pool.Demote("localhost:1")
+	pool.record(pool.endpoints[1], errors.New("query failed"))
+	require.Equal(t, pool.endpoints[1], pool.ranked(false)[0])
+
+	// the demoted endpoint recovers without being queried
+	pool.endpoints[0].updated = time.Now().Add(-10 * errorHalfLife)
+	require.Equal(t, pool.endpoints[0], pool.ranked(false)[0])
+}
+
+func TestEndpointPool_DataError(t *testing.T) {
+	pool, err := NewEndpointPool(zerolog.Nop(), []endpoint.Endpoint{
+		endpoint.New("localhost:1"),
+		endpoint.New("localhost:2"),
+	}, nil, 1, time.Millisecond)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// data errors are neither retried nor penalised
+	noData := NewDataError("no data")
+	attempts := 0
+	err = pool.Do(context.Background(), func(string, PriceSource) error {
+		attempts++
+		return fmt.Errorf("query: %w", noData)
+	})
+	require.ErrorIs(t, err, noData)
+	require.Equal(t, 1, attempts)
+	require.Zero(t, pool.endpoints[0].errorRate)
+	require.Zero(t, pool.Switches(time.Minute))
+
+	_, err = pool.Quorum(context.Background(), 2, func(string, PriceSource) error {
+		return noData
+	})
+	require.NoError(t, err)
+	require.Zero(t, pool.endpoints[0].errorRate)
+	require.Zero(t, pool.endpoints[1].errorRate)
+}
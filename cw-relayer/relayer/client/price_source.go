@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/gogo/protobuf/proto"
+	oracletypes "github.com/ojo-network/ojo/x/oracle/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+)
+
+const (
+	// SourceGRPC queries ojo prices over grpc.
+	SourceGRPC = "grpc"
+	// SourceLCD queries ojo prices from the ojo LCD/REST api.
+	SourceLCD = "lcd"
+
+	lcdExchangeRatesPath    = "/ojo/oracle/v1/denoms/exchange_rates/"
+	lcdMediansPath          = "/ojo/historacle/v1/denoms/medians"
+	lcdMedianDeviationsPath = "/ojo/historacle/v1/denoms/median_deviations"
+	lcdParamsPath           = "/ojo/oracle/v1/params"
+	lcdBlockPath            = "/cosmos/base/tendermint/v1beta1/blocks/"
+
+	// the LCD gateway prefixes grpc response headers
+	lcdHeaderPrefix = "Grpc-Metadata-"
+)
+
+var errNoHeight = fmt.Errorf("block height header not found")
+
+type (
+	// PriceSource queries ojo prices pinned to the given height, or at the latest height
+	// if height is not positive. Every query returns the height it was served at.
+	// Params returns the latest ojo oracle params and BlockTime returns the time of the ojo block
+	// at the given height.
+	PriceSource interface {
+		ExchangeRates(ctx context.Context, height int64) (sdk.DecCoins, int64, error)
+		Medians(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error)
+		MedianDeviations(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error)
+		Params(ctx context.Context) (oracletypes.Params, error)
+		BlockTime(ctx context.Context, height int64) (time.Time, error)
+		Close() error
+	}
+
+	// grpcPriceSource queries prices from the ojo grpc query service.
+	grpcPriceSource struct {
+		conn          *grpc.ClientConn
+		client        oracletypes.QueryClient
+		serviceClient tmservice.ServiceClient
+	}
+
+	// lcdPriceSource queries prices from the ojo LCD/REST api.
+	lcdPriceSource struct {
+		baseURL string
+		client  *http.Client
+		cdc     codec.JSONCodec
+	}
+)
+
+// NewPriceSource returns the price source selected by the endpoint source, grpc is used by default.
+func NewPriceSource(e endpoint.Endpoint) (PriceSource, error) {
+	switch e.Source {
+	case SourceLCD:
+		return newLCDPriceSource(e)
+	case SourceGRPC, "":
+		return newGRPCPriceSource(e)
+	default:
+		return nil, fmt.Errorf("unknown price source %s", e.Source)
+	}
+}
+
+func newGRPCPriceSource(e endpoint.Endpoint) (*grpcPriceSource, error) {
+	conn, err := e.Dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcPriceSource{
+		conn:          conn,
+		client:        oracletypes.NewQueryClient(conn),
+		serviceClient: tmservice.NewServiceClient(conn),
+	}, nil
+}
+
+func (s *grpcPriceSource) ExchangeRates(ctx context.Context, height int64) (sdk.DecCoins, int64, error) {
+	var header metadata.MD
+	resp, err := s.client.ExchangeRates(WithQueryHeight(ctx, height), &oracletypes.QueryExchangeRates{}, grpc.Header(&header))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queryHeight, err := HeaderHeight(header)
+	return resp.ExchangeRates, queryHeight, err
+}
+
+func (s *grpcPriceSource) Medians(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error) {
+	var header metadata.MD
+	resp, err := s.client.Medians(WithQueryHeight(ctx, height), &oracletypes.QueryMedians{}, grpc.Header(&header))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queryHeight, err := HeaderHeight(header)
+	return resp.Medians, queryHeight, err
+}
+
+func (s *grpcPriceSource) MedianDeviations(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error) {
+	var header metadata.MD
+	resp, err := s.client.MedianDeviations(
+		WithQueryHeight(ctx, height),
+		&oracletypes.QueryMedianDeviations{},
+		grpc.Header(&header),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queryHeight, err := HeaderHeight(header)
+	return resp.MedianDeviations, queryHeight, err
+}
+
+func (s *grpcPriceSource) Params(ctx context.Context) (oracletypes.Params, error) {
+	resp, err := s.client.Params(ctx, &oracletypes.QueryParams{})
+	if err != nil {
+		return oracletypes.Params{}, err
+	}
+
+	return resp.Params, nil
+}
+
+func (s *grpcPriceSource) BlockTime(ctx context.Context, height int64) (time.Time, error) {
+	resp, err := s.serviceClient.GetBlockByHeight(ctx, &tmservice.GetBlockByHeightRequest{Height: height})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return blockTime(resp)
+}
+
+func (s *grpcPriceSource) Close() error {
+	return s.conn.Close()
+}
+
+func newLCDPriceSource(e endpoint.Endpoint) (*lcdPriceSource, error) {
+	// request timeouts are set by the query context
+	client, err := e.HTTPClient(0)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseURL string
+	switch e.Scheme() {
+	case endpoint.SchemeHTTP, endpoint.SchemeHTTPS:
+		baseURL = e.Address
+	case endpoint.SchemeUnix:
+		// requests are sent over the unix socket by the client dialer
+		baseURL = "http://unix"
+	case endpoint.SchemeTCP:
+		baseURL = "http://" + strings.TrimPrefix(e.Address, endpoint.SchemeTCP+"://")
+	case "":
+		baseURL = "http://" + e.Address
+	default:
+		return nil, fmt.Errorf("unsupported lcd scheme %s", e.Scheme())
+	}
+
+	return &lcdPriceSource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+		cdc:     codec.NewProtoCodec(codectypes.NewInterfaceRegistry()),
+	}, nil
+}
+
+func (s *lcdPriceSource) ExchangeRates(ctx context.Context, height int64) (sdk.DecCoins, int64, error) {
+	var resp oracletypes.QueryExchangeRatesResponse
+	queryHeight, err := s.get(ctx, lcdExchangeRatesPath, height, &resp)
+	return resp.ExchangeRates, queryHeight, err
+}
+
+func (s *lcdPriceSource) Medians(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error) {
+	var resp oracletypes.QueryMediansResponse
+	queryHeight, err := s.get(ctx, lcdMediansPath, height, &resp)
+	return resp.Medians, queryHeight, err
+}
+
+func (s *lcdPriceSource) MedianDeviations(ctx context.Context, height int64) ([]oracletypes.PriceStamp, int64, error) {
+	var resp oracletypes.QueryMedianDeviationsResponse
+	queryHeight, err := s.get(ctx, lcdMedianDeviationsPath, height, &resp)
+	return resp.MedianDeviations, queryHeight, err
+}
+
+func (s *lcdPriceSource) Params(ctx context.Context) (oracletypes.Params, error) {
+	var resp oracletypes.QueryParamsResponse
+	if _, err := s.get(ctx, lcdParamsPath, 0, &resp); err != nil && err != errNoHeight {
+		return oracletypes.Params{}, err
+	}
+
+	return resp.Params, nil
+}
+
+func (s *lcdPriceSource) BlockTime(ctx context.Context, height int64) (time.Time, error) {
+	var resp tmservice.GetBlockByHeightResponse
+	if _, err := s.get(ctx, lcdBlockPath+strconv.FormatInt(height, 10), 0, &resp); err != nil && err != errNoHeight {
+		return time.Time{}, err
+	}
+
+	return blockTime(&resp)
+}
+
+func (s *lcdPriceSource) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// get queries the LCD path pinned to the height and decodes the json response into resp.
+func (s *lcdPriceSource) get(ctx context.Context, path string, height int64, resp proto.Message) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if height > 0 {
+		req.Header.Set(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
+	}
+
+	httpResp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("lcd query %s failed with status %d: %s", path, httpResp.StatusCode, body)
+	}
+
+	if err := s.cdc.UnmarshalJSON(body, resp); err != nil {
+		return 0, err
+	}
+
+	value := httpResp.Header.Get(lcdHeaderPrefix + grpctypes.GRPCBlockHeightHeader)
+	if len(value) == 0 {
+		value = httpResp.Header.Get(grpctypes.GRPCBlockHeightHeader)
+	}
+
+	if len(value) == 0 {
+		return 0, errNoHeight
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// blockTime returns the header time of the block in the response.
+func blockTime(resp *tmservice.GetBlockByHeightResponse) (time.Time, error) {
+	if resp.Block == nil {
+		return time.Time{}, fmt.Errorf("block not found in response")
+	}
+
+	return resp.Block.Header.Time, nil
+}
+
+// WithQueryHeight pins grpc queries made with the context to the given height,
+// queries are made at the latest height if height is not positive.
+func WithQueryHeight(ctx context.Context, height int64) context.Context {
+	if height <= 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
+}
+
+// HeaderHeight returns the block height a grpc query was served at.
+func HeaderHeight(header metadata.MD) (int64, error) {
+	values := header.Get(grpctypes.GRPCBlockHeightHeader)
+	if len(values) == 0 {
+		return 0, errNoHeight
+	}
+
+	return strconv.ParseInt(values[0], 10, 64)
+}
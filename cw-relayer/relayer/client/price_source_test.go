@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/gogo/protobuf/proto"
+	oracletypes "github.com/ojo-network/ojo/x/oracle/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+)
+
+func TestQueryHeight(t *testing.T) {
+	// queries are pinned to positive heights only
+	md, _ := metadata.FromOutgoingContext(WithQueryHeight(context.Background(), 0))
+	require.Empty(t, md.Get(grpctypes.GRPCBlockHeightHeader))
+
+	md, _ = metadata.FromOutgoingContext(WithQueryHeight(context.Background(), 42))
+	require.Equal(t, []string{"42"}, md.Get(grpctypes.GRPCBlockHeightHeader))
+
+	height, err := HeaderHeight(metadata.Pairs(grpctypes.GRPCBlockHeightHeader, "41"))
+	require.NoError(t, err)
+	require.Equal(t, int64(41), height)
+
+	_, err = HeaderHeight(metadata.MD{})
+	require.ErrorIs(t, err, errNoHeight)
+
+	_, err = HeaderHeight(metadata.Pairs(grpctypes.GRPCBlockHeightHeader, "latest"))
+	require.Error(t, err)
+}
+
+func TestLCDPriceSource(t *testing.T) {
+	rates := sdk.DecCoins{sdk.NewDecCoinFromDec("ATOM", sdk.MustNewDecFromStr("10.5"))}
+	stamps := []oracletypes.PriceStamp{{
+		ExchangeRate: &sdk.DecCoin{Denom: "ATOM", Amount: sdk.MustNewDecFromStr("10.4")},
+		BlockNum:     40,
+	}}
+
+	responses := map[string]proto.Message{
+		lcdExchangeRatesPath:    &oracletypes.QueryExchangeRatesResponse{ExchangeRates: rates},
+		lcdMediansPath:          &oracletypes.QueryMediansResponse{Medians: stamps},
+		lcdMedianDeviationsPath: &oracletypes.QueryMedianDeviationsResponse{MedianDeviations: stamps},
+	}
+
+	noHeight := false
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, found := responses[r.URL.Path]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+
+		// the gateway serves the pinned height, or the latest height if none is set
+		height := r.Header.Get(grpctypes.GRPCBlockHeightHeader)
+		if len(height) == 0 {
+			height = "42"
+		}
+
+		if !noHeight {
+			w.Header().Set(lcdHeaderPrefix+grpctypes.GRPCBlockHeightHeader, height)
+		}
+
+		bz, err := cdc.MarshalJSON(resp)
+		require.NoError(t, err)
+		w.Write(bz) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	source, err := NewPriceSource(endpoint.Endpoint{Address: srv.URL, Source: SourceLCD})
+	require.NoError(t, err)
+	defer source.Close()
+
+	ctx := context.Background()
+
+	exchangeRates, height, err := source.ExchangeRates(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, rates, exchangeRates)
+	require.Equal(t, int64(42), height)
+
+	medians, height, err := source.Medians(ctx, 41)
+	require.NoError(t, err)
+	require.Equal(t, stamps, medians)
+	require.Equal(t, int64(41), height)
+
+	deviations, height, err := source.MedianDeviations(ctx, 41)
+	require.NoError(t, err)
+	require.Equal(t, stamps, deviations)
+	require.Equal(t, int64(41), height)
+
+	// responses without the height header are rejected
+	noHeight = true
+	_, _, err = source.ExchangeRates(ctx, 0)
+	require.ErrorIs(t, err, errNoHeight)
+
+	delete(responses, lcdMediansPath)
+	_, _, err = source.Medians(ctx, 41)
+	require.ErrorContains(t, err, "status 404")
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+
+	"github.com/ojo-network/cw-relayer/pkg/ethermint"
+)
+
+// Names of the built-in chain profiles.
+const (
+	ProfileWasm      = "wasm"
+	ProfileEthermint = "ethermint"
+)
+
+// ChainProfile defines how txs are encoded and signed for a chain: the key algorithm of the keyring,
+// the interfaces registered in addition to the wasmd modules, the account retriever and the sign mode.
+// Programs embedding the relayer can define profiles of chains with custom key or account types.
+type ChainProfile struct {
+	KeyAlgo                  keyring.SignatureAlgo
+	RegisterInterfaces       func(codectypes.InterfaceRegistry)
+	RegisterLegacyAminoCodec func(*codec.LegacyAmino)
+	NewAccountRetriever      func(codec Bech32Codec) client.AccountRetriever
+	SignMode                 signing.SignMode
+}
+
+var chainProfiles = map[string]ChainProfile{
+	ProfileWasm: {
+		KeyAlgo:  hd.Secp256k1,
+		SignMode: signing.SignMode_SIGN_MODE_DIRECT,
+	},
+	// ethermint chains use ethsecp256k1 keys and EthAccount accounts, which are
+	// queried by the standard account retriever once the types are registered
+	ProfileEthermint: {
+		KeyAlgo:                  ethermint.EthSecp256k1,
+		RegisterInterfaces:       ethermint.RegisterInterfaces,
+		RegisterLegacyAminoCodec: ethermint.RegisterLegacyAminoCodec,
+		SignMode:                 signing.SignMode_SIGN_MODE_DIRECT,
+	},
+}
+
+// GetChainProfile returns the built-in chain profile of the name.
+func GetChainProfile(name string) (ChainProfile, error) {
+	profile, ok := chainProfiles[name]
+	if !ok {
+		return ChainProfile{}, fmt.Errorf("unknown chain profile %s", name)
+	}
+
+	return profile, nil
+}
+
+// KeyringOptions returns the keyring options supporting the key algorithm of the profile.
+func (p ChainProfile) KeyringOptions() []keyring.Option {
+	if p.KeyAlgo == nil {
+		return nil
+	}
+
+	return []keyring.Option{func(options *keyring.Options) {
+		options.SupportedAlgos = append(options.SupportedAlgos, p.KeyAlgo)
+	}}
+}
+
+// accountRetriever returns the account retriever of the profile, or the standard account retriever.
+func (p ChainProfile) accountRetriever(codec Bech32Codec) client.AccountRetriever {
+	if p.NewAccountRetriever == nil {
+		return accountRetriever{codec: codec}
+	}
+
+	return p.NewAccountRetriever(codec)
+}
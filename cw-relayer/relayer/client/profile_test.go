@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ojo-network/cw-relayer/pkg/ethermint"
+)
+
+func TestEthermintProfile(t *testing.T) {
+	_, err := GetChainProfile("unknown")
+	require.Error(t, err)
+
+	profile, err := GetChainProfile(ProfileEthermint)
+	require.NoError(t, err)
+
+	encoding := MakeEncodingConfig(profile)
+	kr := keyring.NewInMemory(encoding.Marshaler, profile.KeyringOptions()...)
+	hdPath := hd.CreateHDPath(60, 0, 0).String()
+	record, _, err := kr.NewMnemonic("relayer", keyring.English, hdPath, keyring.DefaultBIP39Passphrase, ethermint.EthSecp256k1)
+	require.NoError(t, err)
+
+	address, err := record.GetAddress()
+	require.NoError(t, err)
+
+	keyringSigner, err := NewKeyringSigner(kr, address)
+	require.NoError(t, err)
+	require.Equal(t, ethermint.KeyType, keyringSigner.PubKey().Type())
+
+	codec := Bech32Codec{Prefix: "evmos"}
+	msg := &banktypes.MsgSend{
+		FromAddress: codec.Encode(address),
+		ToAddress:   codec.Encode(address),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("aevmos", 1)),
+	}
+
+	txf := tx.Factory{}.
+		WithChainID("evmos_9000-1").
+		WithTxConfig(encoding.TxConfig).
+		WithAccountNumber(3).
+		WithSequence(7).
+		WithSignMode(profile.SignMode)
+
+	txBuilder, err := txf.BuildUnsignedTx(msg)
+	require.NoError(t, err)
+	require.NoError(t, signTx(context.Background(), encoding.TxConfig, txf, codec, keyringSigner, txBuilder))
+
+	// the ethsecp256k1 public key is encoded in the signer infos of the tx
+	txBytes, err := encoding.TxConfig.TxEncoder()(txBuilder.GetTx())
+	require.NoError(t, err)
+	decoded, err := encoding.TxConfig.TxDecoder()(txBytes)
+	require.NoError(t, err)
+
+	sigs, err := decoded.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 1)
+	require.True(t, keyringSigner.PubKey().Equals(sigs[0].PubKey))
+
+	signerData := authsigning.SignerData{
+		Address:       codec.Encode(address),
+		ChainID:       "evmos_9000-1",
+		AccountNumber: 3,
+		Sequence:      7,
+		PubKey:        sigs[0].PubKey,
+	}
+	require.NoError(t, authsigning.VerifySignature(
+		sigs[0].PubKey,
+		signerData,
+		sigs[0].Data,
+		encoding.TxConfig.SignModeHandler(),
+		decoded,
+	))
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+	"github.com/ojo-network/cw-relayer/pkg/httputil"
+	"github.com/ojo-network/cw-relayer/pkg/signer"
+)
+
+type (
+	// Signer signs txs with the key of an account.
+	Signer interface {
+		Address() sdk.AccAddress
+		PubKey() cryptotypes.PubKey
+		Sign(ctx context.Context, signBytes []byte) ([]byte, error)
+	}
+
+	// KeyringSigner signs with a key of the local keyring.
+	KeyringSigner struct {
+		keyring keyring.Keyring
+		address sdk.AccAddress
+		pubKey  cryptotypes.PubKey
+	}
+
+	// RemoteSigner signs with a key held by a remote signer, see the signer package for the protocol.
+	RemoteSigner struct {
+		url     string
+		client  *http.Client
+		address sdk.AccAddress
+		bech32  string
+		pubKey  cryptotypes.PubKey
+	}
+)
+
+// NewKeyringSigner returns a signer of the address, the key of the address must be in the keyring.
+func NewKeyringSigner(kr keyring.Keyring, address sdk.AccAddress) (*KeyringSigner, error) {
+	record, err := kr.KeyByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := record.GetPubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyringSigner{keyring: kr, address: address, pubKey: pubKey}, nil
+}
+
+// Address returns the address of the signer key.
+func (s *KeyringSigner) Address() sdk.AccAddress {
+	return s.address
+}
+
+// PubKey returns the public key of the signer key.
+func (s *KeyringSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+// Sign signs the bytes with the keyring key.
+func (s *KeyringSigner) Sign(_ context.Context, signBytes []byte) ([]byte, error) {
+	signature, _, err := s.keyring.SignByAddress(s.address, signBytes)
+	return signature, err
+}
+
+// NewRemoteSigner returns a signer of the address with the key held by the remote signer at the endpoint,
+// supporting http://, https://, tcp:// and unix:// addresses. The public key is queried once, and the
+// address is sent to the remote signer encoded with the codec.
+func NewRemoteSigner(
+	ctx context.Context,
+	e endpoint.Endpoint,
+	timeout time.Duration,
+	codec Bech32Codec,
+	address sdk.AccAddress,
+) (*RemoteSigner, error) {
+	client, err := e.HTTPClient(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &RemoteSigner{
+		url:     signerURL(e),
+		client:  client,
+		address: address,
+		bech32:  codec.Encode(address),
+	}
+
+	var resp signer.PubKeyResponse
+	if err := s.post(ctx, signer.PathPubKey, signer.PubKeyRequest{Address: s.bech32}, &resp); err != nil {
+		return nil, fmt.Errorf("error querying remote signer public key: %w", err)
+	}
+
+	s.pubKey, err = signer.DecodePubKey(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(s.pubKey.Address(), address) {
+		return nil, fmt.Errorf("remote signer public key does not match address %s", s.bech32)
+	}
+
+	return s, nil
+}
+
+// Address returns the address of the signer key.
+func (s *RemoteSigner) Address() sdk.AccAddress {
+	return s.address
+}
+
+// PubKey returns the public key of the signer key.
+func (s *RemoteSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+// Sign requests a signature of the bytes from the remote signer, and verifies it.
+func (s *RemoteSigner) Sign(ctx context.Context, signBytes []byte) ([]byte, error) {
+	var resp signer.SignResponse
+	if err := s.post(ctx, signer.PathSign, signer.SignRequest{Address: s.bech32, SignBytes: signBytes}, &resp); err != nil {
+		return nil, fmt.Errorf("error requesting remote signature: %w", err)
+	}
+
+	if !s.pubKey.VerifySignature(signBytes, resp.Signature) {
+		return nil, fmt.Errorf("invalid remote signature")
+	}
+
+	return resp.Signature, nil
+}
+
+// post sends the request to the path of the remote signer and decodes the response.
+func (s *RemoteSigner) post(ctx context.Context, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp httputil.ErrResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil || len(errResp.Error) == 0 {
+			return fmt.Errorf("remote signer responded with status %d", resp.StatusCode)
+		}
+
+		return fmt.Errorf("remote signer responded with status %d: %s", resp.StatusCode, errResp.Error)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// signerURL returns the base url of the remote signer, requests to unix sockets
+// are sent to a placeholder host as the socket is dialed by the client transport.
+func signerURL(e endpoint.Endpoint) string {
+	switch e.Scheme() {
+	case endpoint.SchemeHTTP, endpoint.SchemeHTTPS:
+		return strings.TrimSuffix(e.Address, "/")
+	case endpoint.SchemeUnix:
+		return "http://unix"
+	default:
+		return "http://" + strings.TrimPrefix(e.Address, endpoint.SchemeTCP+"://")
+	}
+}
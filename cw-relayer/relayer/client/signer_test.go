@@ -0,0 +1,309 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/ante"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+	"github.com/ojo-network/cw-relayer/pkg/signer"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	kr := keyring.NewInMemory(MakeEncodingConfig(chainProfiles[ProfileWasm]).Marshaler)
+	record, _, err := kr.NewMnemonic("relayer", keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+	require.NoError(t, err)
+
+	address, err := record.GetAddress()
+	require.NoError(t, err)
+
+	codec := Bech32Codec{Prefix: "wasm"}
+	server := httptest.NewTLSServer(signer.NewServer(zerolog.Nop(), kr, codec.Prefix, "secret"))
+	defer server.Close()
+
+	// bearer tokens are only sent over TLS
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	ctx := context.Background()
+
+	// requests without the token are rejected
+	e := endpoint.Endpoint{Address: server.URL, CAFile: caFile}
+	_, err = NewRemoteSigner(ctx, e, time.Second, codec, address)
+	require.ErrorContains(t, err, "401")
+
+	e.Token = "secret"
+	remote, err := NewRemoteSigner(ctx, e, time.Second, codec, address)
+	require.NoError(t, err)
+
+	local, err := NewKeyringSigner(kr, address)
+	require.NoError(t, err)
+	require.Equal(t, local.PubKey(), remote.PubKey())
+
+	signature, err := remote.Sign(ctx, []byte("sign bytes"))
+	require.NoError(t, err)
+	require.True(t, local.PubKey().VerifySignature([]byte("sign bytes"), signature))
+
+	// keys which are not in the signer keyring are rejected
+	_, err = NewRemoteSigner(ctx, e, time.Second, codec, sdk.AccAddress("unknown"))
+	require.Error(t, err)
+
+	// addresses with another prefix than the signer prefix are rejected
+	_, err = NewRemoteSigner(ctx, e, time.Second, Bech32Codec{Prefix: "juno"}, address)
+	require.ErrorContains(t, err, "400")
+}
+
+func TestSignTx(t *testing.T) {
+	encoding := MakeEncodingConfig(chainProfiles[ProfileWasm])
+	kr := keyring.NewInMemory(encoding.Marshaler)
+	record, _, err := kr.NewMnemonic("relayer", keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+	require.NoError(t, err)
+
+	address, err := record.GetAddress()
+	require.NoError(t, err)
+
+	keyringSigner, err := NewKeyringSigner(kr, address)
+	require.NoError(t, err)
+
+	// addresses are encoded with the codec prefix, not the global sdk config
+	codec := Bech32Codec{Prefix: "juno"}
+	msg := &banktypes.MsgSend{
+		FromAddress: codec.Encode(address),
+		ToAddress:   codec.Encode(address),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("stake", 1)),
+	}
+	granter := codec.Encode(sdk.AccAddress("granter"))
+
+	for _, signMode := range []signing.SignMode{signing.SignMode_SIGN_MODE_DIRECT, signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON} {
+		txf := tx.Factory{}.
+			WithChainID("wasm").
+			WithTxConfig(encoding.TxConfig).
+			WithAccountNumber(3).
+			WithSequence(7).
+			WithSignMode(signMode)
+
+		txBuilder, err := txf.BuildUnsignedTx(msg)
+		require.NoError(t, err)
+		require.NoError(t, setFeeGranter(txBuilder, granter))
+		require.NoError(t, signTx(context.Background(), encoding.TxConfig, txf, codec, keyringSigner, txBuilder))
+
+		txBytes, err := encoding.TxConfig.TxEncoder()(txBuilder.GetTx())
+		require.NoError(t, err)
+		decoded, err := encoding.TxConfig.TxDecoder()(txBytes)
+		require.NoError(t, err)
+		require.Equal(t, granter, decoded.(interface{ GetProtoTx() *txtypes.Tx }).GetProtoTx().AuthInfo.Fee.Granter)
+
+		sigs, err := txBuilder.GetTx().GetSignaturesV2()
+		require.NoError(t, err)
+		require.Len(t, sigs, 1)
+		require.Equal(t, uint64(7), sigs[0].Sequence)
+
+		signerData := authsigning.SignerData{
+			Address:       codec.Encode(address),
+			ChainID:       "wasm",
+			AccountNumber: 3,
+			Sequence:      7,
+			PubKey:        keyringSigner.PubKey(),
+		}
+		require.NoError(t, authsigning.VerifySignature(
+			keyringSigner.PubKey(),
+			signerData,
+			sigs[0].Data,
+			encoding.TxConfig.SignModeHandler(),
+			txBuilder.GetTx(),
+		))
+	}
+}
+
+// failingSigner is a multisig member which fails to sign.
+type failingSigner struct {
+	Signer
+}
+
+func (failingSigner) Sign(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("signer unavailable")
+}
+
+func TestMultisigSigner(t *testing.T) {
+	encoding := MakeEncodingConfig(chainProfiles[ProfileWasm])
+	kr := keyring.NewInMemory(encoding.Marshaler)
+
+	var members []Signer
+	for _, name := range []string{"a", "b", "c"} {
+		record, _, err := kr.NewMnemonic(name, keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+		require.NoError(t, err)
+
+		address, err := record.GetAddress()
+		require.NoError(t, err)
+
+		member, err := NewKeyringSigner(kr, address)
+		require.NoError(t, err)
+
+		members = append(members, member)
+	}
+
+	// the multisig address is derived from the members sorted by address
+	sorted := append([]Signer{}, members...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i].Address(), sorted[j].Address()) < 0 })
+	pubKeys := []cryptotypes.PubKey{sorted[0].PubKey(), sorted[1].PubKey(), sorted[2].PubKey()}
+	address := sdk.AccAddress(kmultisig.NewLegacyAminoPubKey(2, pubKeys).Address())
+
+	codec := Bech32Codec{Prefix: "wasm"}
+	_, err := NewMultisigSigner(zerolog.Nop(), codec, sdk.AccAddress("other"), 2, members)
+	require.Error(t, err)
+
+	// the first member fails, the tx is signed by the other two
+	members[0] = failingSigner{Signer: members[0]}
+	multisigSigner, err := NewMultisigSigner(zerolog.Nop(), codec, address, 2, members)
+	require.NoError(t, err)
+	require.Equal(t, address, multisigSigner.Address())
+
+	txf := tx.Factory{}.
+		WithChainID("wasm").
+		WithTxConfig(encoding.TxConfig).
+		WithAccountNumber(3).
+		WithSequence(7)
+
+	msg := &banktypes.MsgSend{
+		FromAddress: codec.Encode(address),
+		ToAddress:   codec.Encode(address),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("stake", 1)),
+	}
+	txBuilder, err := txf.BuildUnsignedTx(msg)
+	require.NoError(t, err)
+	require.NoError(t, multisigSigner.SignTx(context.Background(), encoding.TxConfig, txf, txBuilder))
+
+	sigs, err := txBuilder.GetTx().GetSignaturesV2()
+	require.NoError(t, err)
+	require.Len(t, sigs, 1)
+
+	signerData := authsigning.SignerData{
+		Address:       codec.Encode(address),
+		ChainID:       "wasm",
+		AccountNumber: 3,
+		Sequence:      7,
+		PubKey:        sigs[0].PubKey,
+	}
+	require.NoError(t, authsigning.VerifySignature(
+		sigs[0].PubKey,
+		signerData,
+		sigs[0].Data,
+		encoding.TxConfig.SignModeHandler(),
+		txBuilder.GetTx(),
+	))
+
+	// the threshold cannot be reached with two failing members
+	members[1] = failingSigner{Signer: members[1]}
+	multisigSigner, err = NewMultisigSigner(zerolog.Nop(), codec, address, 2, members)
+	require.NoError(t, err)
+	require.Error(t, multisigSigner.SignTx(context.Background(), encoding.TxConfig, txf, txBuilder))
+}
+
+// multisigAccountKeeper returns the multisig account with its public key set on chain.
+type multisigAccountKeeper struct {
+	account authtypes.AccountI
+}
+
+func (multisigAccountKeeper) GetParams(sdk.Context) authtypes.Params {
+	return authtypes.DefaultParams()
+}
+
+func (k multisigAccountKeeper) GetAccount(sdk.Context, sdk.AccAddress) authtypes.AccountI {
+	return k.account
+}
+
+func (multisigAccountKeeper) SetAccount(sdk.Context, authtypes.AccountI) {}
+
+func (multisigAccountKeeper) GetModuleAddress(string) sdk.AccAddress { return nil }
+
+func TestMultisigSimulation(t *testing.T) {
+	encoding := MakeEncodingConfig(chainProfiles[ProfileWasm])
+	kr := keyring.NewInMemory(encoding.Marshaler)
+
+	var members []Signer
+	for _, name := range []string{"a", "b", "c"} {
+		record, _, err := kr.NewMnemonic(name, keyring.English, sdk.FullFundraiserPath, keyring.DefaultBIP39Passphrase, hd.Secp256k1)
+		require.NoError(t, err)
+
+		address, err := record.GetAddress()
+		require.NoError(t, err)
+
+		member, err := NewKeyringSigner(kr, address)
+		require.NoError(t, err)
+
+		members = append(members, member)
+	}
+
+	pubKeys := []cryptotypes.PubKey{members[0].PubKey(), members[1].PubKey(), members[2].PubKey()}
+	pubKey := kmultisig.NewLegacyAminoPubKey(2, pubKeys)
+	address := sdk.AccAddress(pubKey.Address())
+
+	// msg signers are decoded with the global sdk config
+	codec := Bech32Codec{Prefix: sdk.GetConfig().GetBech32AccountAddrPrefix()}
+	multisigSigner, err := NewMultisigSigner(zerolog.Nop(), codec, address, 2, members)
+	require.NoError(t, err)
+
+	// the multisig key is set on chain by the first multisig tx
+	account := authtypes.NewBaseAccount(address, pubKey, 3, 7)
+	decorator := ante.NewSigGasConsumeDecorator(multisigAccountKeeper{account: account}, ante.DefaultSigVerificationGasConsumer)
+
+	txf := tx.Factory{}.
+		WithChainID("wasm").
+		WithTxConfig(encoding.TxConfig).
+		WithAccountNumber(3).
+		WithSequence(7)
+
+	msg := &banktypes.MsgSend{
+		FromAddress: codec.Encode(address),
+		ToAddress:   codec.Encode(address),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("stake", 1)),
+	}
+
+	simulate := func(signer TxSigner) (uint64, error) {
+		txBuilder, err := buildSimTx(txf, "", signer, msg)
+		require.NoError(t, err)
+
+		txBytes, err := encoding.TxConfig.TxEncoder()(txBuilder.GetTx())
+		require.NoError(t, err)
+		decoded, err := encoding.TxConfig.TxDecoder()(txBytes)
+		require.NoError(t, err)
+
+		ctx := sdk.Context{}.WithGasMeter(sdk.NewInfiniteGasMeter())
+		_, err = decorator.AnteHandle(ctx, decoded, true, func(ctx sdk.Context, _ sdk.Tx, _ bool) (sdk.Context, error) {
+			return ctx, nil
+		})
+
+		return ctx.GasMeter().GasConsumed(), err
+	}
+
+	// the verification gas of the threshold member keys is consumed
+	gas, err := simulate(multisigSigner)
+	require.NoError(t, err)
+	require.Equal(t, 2*authtypes.DefaultSigVerifyCostSecp256k1, gas)
+
+	// a single signature does not match the multisig key on chain
+	_, err = simulate(NewKeySigner(codec, members[0]))
+	require.Error(t, err)
+}
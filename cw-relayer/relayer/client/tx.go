@@ -1,25 +1,63 @@
 package client
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+)
+
+type (
+	// TxSigner signs txs of an account, with a single key or with a multisig.
+	TxSigner interface {
+		Address() sdk.AccAddress
+		SignTx(ctx context.Context, txConfig client.TxConfig, txf tx.Factory, txBuilder client.TxBuilder) error
+	}
+
+	// keySigner signs txs with the single key of a Signer.
+	keySigner struct {
+		Signer
+		codec Bech32Codec
+	}
 )
 
+// NewKeySigner returns a tx signer signing with the single key of the signer,
+// addresses are encoded with the codec.
+func NewKeySigner(codec Bech32Codec, signer Signer) TxSigner {
+	return keySigner{Signer: signer, codec: codec}
+}
+
+// SignTx signs the tx with the key of the signer.
+func (s keySigner) SignTx(ctx context.Context, txConfig client.TxConfig, txf tx.Factory, txBuilder client.TxBuilder) error {
+	return signTx(ctx, txConfig, txf, s.codec, s.Signer, txBuilder)
+}
+
 // BroadcastTx attempts to generate, sign and broadcast a transaction with the
 // given set of messages. It will also simulate gas requirements if necessary.
 // It will return an error upon failure.
 //
 // Note, BroadcastTx is copied from the SDK except it removes a few unnecessary
 // things like prompting for confirmation and printing the response. Instead,
-// we return the TxResponse.
-func BroadcastTx(feeGranter sdk.AccAddress, clientCtx client.Context, txf tx.Factory, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+// we return the TxResponse. The tx is signed by the signer instead of the keyring.
+func BroadcastTx(
+	feeGranter string,
+	clientCtx client.Context,
+	txf tx.Factory,
+	signer TxSigner,
+	msgs ...sdk.Msg,
+) (*sdk.TxResponse, error) {
 	txf, err := prepareFactory(clientCtx, txf)
 	if err != nil {
 		return nil, err
 	}
 
-	_, adjusted, err := tx.CalculateGas(clientCtx, txf, msgs...)
+	adjusted, err := calculateGas(clientCtx, txf, feeGranter, signer, msgs...)
 	if err != nil {
 		return nil, err
 	}
@@ -31,9 +69,11 @@ func BroadcastTx(feeGranter sdk.AccAddress, clientCtx client.Context, txf tx.Fac
 		return nil, err
 	}
 
-	unsignedTx.SetFeeGranter(feeGranter)
+	if err := setFeeGranter(unsignedTx, feeGranter); err != nil {
+		return nil, err
+	}
 
-	if err = tx.Sign(txf, clientCtx.GetFromName(), unsignedTx, true); err != nil {
+	if err = signer.SignTx(context.Background(), clientCtx.TxConfig, txf, unsignedTx); err != nil {
 		return nil, err
 	}
 
@@ -45,6 +85,144 @@ func BroadcastTx(feeGranter sdk.AccAddress, clientCtx client.Context, txf tx.Fac
 	return clientCtx.BroadcastTx(txBytes)
 }
 
+// calculateGas simulates the tx and returns the adjusted gas estimate.
+//
+// Note, calculateGas is copied from the SDK tx.CalculateGas except the simulated tx
+// carries the fee granter and the public key of the signer.
+func calculateGas(
+	clientCtx client.Context,
+	txf tx.Factory,
+	feeGranter string,
+	signer TxSigner,
+	msgs ...sdk.Msg,
+) (uint64, error) {
+	txBuilder, err := buildSimTx(txf, feeGranter, signer, msgs...)
+	if err != nil {
+		return 0, err
+	}
+
+	txBytes, err := clientCtx.TxConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return 0, err
+	}
+
+	simRes, err := txtypes.NewServiceClient(clientCtx).Simulate(context.Background(), &txtypes.SimulateRequest{TxBytes: txBytes})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
+}
+
+// buildSimTx returns the unsigned tx of the simulation, with the empty signature of the signer.
+func buildSimTx(txf tx.Factory, feeGranter string, signer TxSigner, msgs ...sdk.Msg) (client.TxBuilder, error) {
+	txBuilder, err := txf.BuildUnsignedTx(msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setFeeGranter(txBuilder, feeGranter); err != nil {
+		return nil, err
+	}
+
+	// the ante handler skips signature verification in simulations, but consumes
+	// the verification gas of the public key type
+	if err := txBuilder.SetSignatures(simSignature(signer, txf)); err != nil {
+		return nil, err
+	}
+
+	return txBuilder, nil
+}
+
+// simSignature returns the empty signature of a simulated tx. Single key signers are simulated with
+// their public key, and multisigs with the multisig key and threshold empty member signatures, as the
+// ante handler consumes the gas of the account key once it is set on chain. Other signers are
+// simulated with the default secp256k1 key of the SDK simulation.
+func simSignature(signer TxSigner, txf tx.Factory) signing.SignatureV2 {
+	switch s := signer.(type) {
+	case *MultisigSigner:
+		return s.simSignature(txf.Sequence())
+
+	case Signer:
+		return signing.SignatureV2{
+			PubKey:   s.PubKey(),
+			Data:     &signing.SingleSignatureData{SignMode: txf.SignMode()},
+			Sequence: txf.Sequence(),
+		}
+
+	default:
+		return signing.SignatureV2{
+			PubKey:   &secp256k1.PubKey{},
+			Data:     &signing.SingleSignatureData{SignMode: txf.SignMode()},
+			Sequence: txf.Sequence(),
+		}
+	}
+}
+
+// setFeeGranter sets the bech32 fee granter of the tx. The tx builder encodes the fee granter with
+// the global sdk config, so the address is set on the proto tx instead.
+func setFeeGranter(txBuilder client.TxBuilder, feeGranter string) error {
+	protoTx, ok := txBuilder.(interface{ GetProtoTx() *txtypes.Tx })
+	if !ok {
+		return fmt.Errorf("unexpected tx builder %T", txBuilder)
+	}
+
+	// resets the cached auth info bytes of the builder
+	txBuilder.SetFeeGranter(nil)
+	protoTx.GetProtoTx().AuthInfo.Fee.Granter = feeGranter
+
+	return nil
+}
+
+// signTx signs the tx with the signer, overwriting any existing signatures.
+//
+// Note, signTx is copied from the SDK tx.Sign except the sign bytes are signed by the signer.
+func signTx(
+	ctx context.Context,
+	txConfig client.TxConfig,
+	txf tx.Factory,
+	codec Bech32Codec,
+	signer Signer,
+	txBuilder client.TxBuilder,
+) error {
+	signMode := txf.SignMode()
+	if signMode == signing.SignMode_SIGN_MODE_UNSPECIFIED {
+		signMode = txConfig.SignModeHandler().DefaultMode()
+	}
+
+	signerData := authsigning.SignerData{
+		Address:       codec.Encode(signer.Address()),
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+		PubKey:        signer.PubKey(),
+	}
+
+	// the sign bytes include the signer infos, so an empty signature
+	// with the public key and sequence is set before signing
+	sig := signing.SignatureV2{
+		PubKey:   signer.PubKey(),
+		Data:     &signing.SingleSignatureData{SignMode: signMode},
+		Sequence: txf.Sequence(),
+	}
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return err
+	}
+
+	signBytes, err := txConfig.SignModeHandler().GetSignBytes(signMode, signerData, txBuilder.GetTx())
+	if err != nil {
+		return err
+	}
+
+	signature, err := signer.Sign(ctx, signBytes)
+	if err != nil {
+		return err
+	}
+
+	sig.Data = &signing.SingleSignatureData{SignMode: signMode, Signature: signature}
+	return txBuilder.SetSignatures(sig)
+}
+
 // prepareFactory ensures the account defined by ctx.GetFromAddress() exists and
 // if the account number and/or the account sequence number are zero (not set),
 // they will be queried for and set on the provided Factory. A new Factory with
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	cmtjson "github.com/cometbft/cometbft/libs/json"
+	tmctypes "github.com/cometbft/cometbft/rpc/core/types"
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
+)
+
+const subscriptionID = rpctypes.JSONRPCStringID("cw-relayer")
+
+// eventSubscription is a websocket subscription to the events of a tendermint rpc, dialed with
+// the transport options of the endpoint. Events is closed when the connection fails or is closed.
+type eventSubscription struct {
+	logger    zerolog.Logger
+	conn      *websocket.Conn
+	Events    chan tmctypes.ResultEvent
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// subscribeEvents subscribes to the events of the query, the subscription
+// is confirmed by the rpc before returning.
+func subscribeEvents(
+	ctx context.Context,
+	logger zerolog.Logger,
+	rpc endpoint.Endpoint,
+	query string,
+	timeout time.Duration,
+) (*eventSubscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := rpc.DialWebsocket(ctx, wsEndpoint, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := rpctypes.MapToRequest(subscriptionID, "subscribe", map[string]interface{}{"query": query})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := conn.WriteJSON(request); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	var response rpctypes.RPCResponse
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := conn.ReadJSON(&response); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if response.Error != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", query, response.Error)
+	}
+
+	// events are awaited without deadline, stale subscriptions are detected by the max tick timeout
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	subscription := &eventSubscription{
+		logger: logger,
+		conn:   conn,
+		Events: make(chan tmctypes.ResultEvent, 100),
+		done:   make(chan struct{}),
+	}
+
+	go subscription.read()
+
+	return subscription, nil
+}
+
+// read decodes the events of the subscription until the connection fails or is closed.
+func (s *eventSubscription) read() {
+	defer close(s.Events)
+
+	for {
+		var response rpctypes.RPCResponse
+		if err := s.conn.ReadJSON(&response); err != nil {
+			s.logger.Debug().Err(err).Msg("websocket subscription closed")
+			return
+		}
+
+		if response.Error != nil {
+			s.logger.Error().Err(response.Error).Msg("websocket subscription error")
+			continue
+		}
+
+		var result tmctypes.ResultEvent
+		if err := cmtjson.Unmarshal(response.Result, &result); err != nil {
+			s.logger.Err(err).Msg("failed to decode event")
+			continue
+		}
+
+		// events are dropped once the subscription is closed, as they are no longer drained
+		select {
+		case s.Events <- result:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Close closes the websocket connection, dropping the subscription on the rpc,
+// and stops the reader of the subscription.
+func (s *eventSubscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+
+	return err
+}
@@ -0,0 +1,86 @@
+package relayer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+)
+
+const (
+	// CompletenessRelay relays the partial set of rates and logs a warning.
+	CompletenessRelay = "relay"
+	// CompletenessHold holds back the relay until all the expected denoms are returned by ojo.
+	CompletenessHold = "hold"
+	// CompletenessAlert relays the partial set of rates and raises an alert.
+	CompletenessAlert = "alert"
+)
+
+// CompletenessConfig defines the denoms expected in every relay, and the policy applied when
+// ojo does not return some of them. The ojo accept list is expected if AcceptList is set,
+// in addition to ExpectedDenoms. The check is disabled if no denoms are expected.
+type CompletenessConfig struct {
+	ExpectedDenoms []string
+	AcceptList     bool
+	Policy         string
+}
+
+// expectedDenoms returns the denoms expected in a relay of the profile.
+func (r *Relayer) expectedDenoms(profile RelayProfile) []string {
+	expected := append([]string{}, r.completeness.ExpectedDenoms...)
+	if r.completeness.AcceptList {
+		expected = append(expected, r.params.acceptList...)
+	}
+
+	return profile.filterDenoms(expected)
+}
+
+// checkCompleteness reports the expected denoms missing from the rates, and returns an error
+// if the relay must be held back.
+func (r *Relayer) checkCompleteness(profile RelayProfile, rates types.DecCoins) error {
+	missing := missingDenoms(r.expectedDenoms(profile), rates)
+	if len(missing) == 0 {
+		r.resolveAlert(AlertMissingDenoms)
+		return nil
+	}
+
+	telemetry.IncrCounter(float32(len(missing)), "failure", "missing_denoms")
+	switch r.completeness.Policy {
+	case CompletenessHold:
+		return fmt.Errorf("ojo rates missing denoms %s, holding relay", strings.Join(missing, ","))
+
+	case CompletenessAlert:
+		r.logger.Error().Strs("missing denoms", missing).Msg("ojo rates missing expected denoms")
+		r.alert(AlertMissingDenoms, alert.SeverityWarning, "ojo rates missing expected denoms", map[string]string{
+			"missing denoms": strings.Join(missing, ","),
+		})
+
+	default:
+		r.logger.Warn().Strs("missing denoms", missing).Msg("ojo rates missing expected denoms, relaying partial set")
+	}
+
+	return nil
+}
+
+// missingDenoms returns the expected denoms without a rate, denoms are compared case-insensitively.
+func missingDenoms(expected []string, rates types.DecCoins) []string {
+	found := make(map[string]bool, len(rates))
+	for _, rate := range rates {
+		found[strings.ToUpper(rate.Denom)] = true
+	}
+
+	var missing []string
+	for _, denom := range expected {
+		denom = strings.ToUpper(denom)
+		if !found[denom] {
+			missing = append(missing, denom)
+			// report each denom once
+			found[denom] = true
+		}
+	}
+
+	return missing
+}
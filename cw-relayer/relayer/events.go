@@ -0,0 +1,80 @@
+package relayer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	attributeKeyDenom = "denom"
+	attributeKeyRate  = "rate"
+)
+
+// decodeEventRates decodes exchange rates from the denom and rate attributes of ojo events.
+// Events without both attributes are ignored, if a denom is repeated the last rate is used.
+func decodeEventRates(events []abcitypes.Event) (types.DecCoins, error) {
+	rates := map[string]types.Dec{}
+	for _, event := range events {
+		var denom, rate string
+		for _, attribute := range event.Attributes {
+			switch attribute.Key {
+			case attributeKeyDenom:
+				denom = decodeAttributeValue(attribute.Value)
+			case attributeKeyRate:
+				rate = decodeAttributeValue(attribute.Value)
+			}
+		}
+
+		if len(denom) == 0 || len(rate) == 0 {
+			continue
+		}
+
+		if err := types.ValidateDenom(denom); err != nil {
+			return nil, err
+		}
+
+		amount, err := types.NewDecFromStr(rate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s: %w", denom, err)
+		}
+
+		if amount.IsNegative() {
+			return nil, fmt.Errorf("negative rate for %s", denom)
+		}
+
+		rates[denom] = amount
+	}
+
+	denoms := make([]string, 0, len(rates))
+	for denom := range rates {
+		denoms = append(denoms, denom)
+	}
+	sort.Strings(denoms)
+
+	coins := make([]types.DecCoin, len(denoms))
+	for i, denom := range denoms {
+		coins[i] = types.NewDecCoinFromDec(denom, rates[denom])
+	}
+
+	return types.NewDecCoins(coins...), nil
+}
+
+// decodeAttributeValue returns the attribute value, typed events emit
+// json encoded values so quoted strings are unmarshalled.
+func decodeAttributeValue(value string) string {
+	if !strings.HasPrefix(value, `"`) {
+		return value
+	}
+
+	var decoded string
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return value
+	}
+
+	return decoded
+}
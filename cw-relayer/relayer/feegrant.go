@@ -0,0 +1,149 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+	"github.com/ojo-network/cw-relayer/relayer/client"
+)
+
+// FeeGrantConfig defines the monitoring of the fee allowance granted to the relayer, which is queried
+// on startup, every Interval and whenever a tx is rejected by the allowance. It is alerted ExpiryWarning
+// before it expires, and when its remaining spend limit is below SpendWarning in any of its denoms.
+// If Fallback is set, txs are paid by the relayer while the allowance is missing or expired, instead of failing.
+type FeeGrantConfig struct {
+	Interval      time.Duration
+	ExpiryWarning time.Duration
+	SpendWarning  types.Coins
+	Fallback      bool
+}
+
+// monitorFeeGrant checks the fee allowance every interval, and when a check is requested
+// by requestFeeGrantCheck, until the context is done.
+func (r *Relayer) monitorFeeGrant(ctx context.Context) {
+	ticker := time.NewTicker(r.feeGrant.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.feeGrantCheck:
+		}
+
+		if err := r.checkFeeGrant(ctx); err != nil {
+			r.logger.Err(err).Msg("fee grant check failed")
+		}
+	}
+}
+
+// requestFeeGrantCheck requests a fee allowance check if the tx error is a rejection by the allowance,
+// so that fees are paid by the relayer on the next relay if fallback is enabled.
+func (r *Relayer) requestFeeGrantCheck(err error) {
+	if !errors.Is(err, client.ErrFeeGrant) {
+		return
+	}
+
+	select {
+	case r.feeGrantCheck <- struct{}{}:
+	default:
+	}
+}
+
+// checkFeeGrant queries the fee allowance, exports its spend limit and expiry, and returns an error
+// if the allowance is missing or expired. Fees are paid by the relayer while the allowance is
+// not usable if fallback is enabled.
+func (r *Relayer) checkFeeGrant(ctx context.Context) error {
+	now := time.Now()
+	allowance, err := r.relayerClient.FeeAllowance(ctx, r.queryTimeout)
+	if err != nil && !errors.Is(err, client.ErrNoFeeAllowance) {
+		telemetry.IncrCounter(1, "failure", "fee_grant")
+		return err
+	}
+
+	if err == nil && (allowance.Expiration == nil || allowance.Expiration.After(now)) {
+		r.feeGrantUsable(allowance, now)
+		return nil
+	}
+
+	if err == nil {
+		err = fmt.Errorf("fee allowance expired at %s", allowance.Expiration.UTC().Format(time.RFC3339))
+	}
+
+	r.alert(AlertFeeGrant, alert.SeverityCritical, "fee allowance is not usable", map[string]string{
+		"error":    err.Error(),
+		"fallback": fmt.Sprint(r.feeGrant.Fallback),
+	})
+
+	if r.feeGrant.Fallback && r.relayerClient.FeeGranter() != nil {
+		r.relayerClient.SetSelfPaidFees(true)
+		r.logger.Warn().Err(err).Msg("paying fees from the relayer account")
+	}
+
+	return err
+}
+
+// feeGrantUsable exports the spend limit and expiry of the usable allowance, alerts if it expires soon,
+// and switches back to the fee granter if fees were paid by the relayer.
+func (r *Relayer) feeGrantUsable(allowance client.FeeAllowance, now time.Time) {
+	if r.relayerClient.FeeGranter() == nil {
+		r.relayerClient.SetSelfPaidFees(false)
+		r.logger.Info().Msg("fee allowance restored, paying fees from the fee granter")
+	}
+
+	r.resolveAlert(AlertFeeGrant)
+
+	for _, coin := range allowance.SpendLimit {
+		telemetry.SetGauge(float32(types.NewDecFromInt(coin.Amount).MustFloat64()), "fee_grant", "spend_limit", coin.Denom)
+	}
+
+	if low := lowSpendLimit(allowance.SpendLimit, r.feeGrant.SpendWarning); !low.Empty() {
+		r.logger.Warn().Str("spend limit", allowance.SpendLimit.String()).Msg("fee allowance spend limit is low")
+		r.alert(AlertFeeGrantSpend, alert.SeverityWarning, "fee allowance spend limit is low", map[string]string{
+			"spend limit": allowance.SpendLimit.String(),
+			"low denoms":  low.String(),
+		})
+	} else {
+		r.resolveAlert(AlertFeeGrantSpend)
+	}
+
+	if allowance.Expiration == nil {
+		return
+	}
+
+	expiresIn := allowance.Expiration.Sub(now)
+	telemetry.SetGauge(float32(expiresIn.Seconds()), "fee_grant", "expiry")
+	if expiresIn > r.feeGrant.ExpiryWarning {
+		r.resolveAlert(AlertFeeGrantExpiry)
+		return
+	}
+
+	r.logger.Warn().Time("expiration", *allowance.Expiration).Msg("fee allowance expires soon")
+	r.alert(AlertFeeGrantExpiry, alert.SeverityWarning, "fee allowance expires soon", map[string]string{
+		"expiration": allowance.Expiration.UTC().Format(time.RFC3339),
+	})
+}
+
+// lowSpendLimit returns the warning thresholds the spend limit is below, an unlimited (nil)
+// spend limit is never low.
+func lowSpendLimit(spendLimit, warning types.Coins) types.Coins {
+	if spendLimit == nil {
+		return nil
+	}
+
+	var low types.Coins
+	for _, threshold := range warning {
+		if spendLimit.AmountOf(threshold.Denom).LT(threshold.Amount) {
+			low = append(low, threshold)
+		}
+	}
+
+	return low
+}
@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"encoding/json"
+	"fmt"
 
 	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	"github.com/cosmos/cosmos-sdk/types"
@@ -71,6 +72,13 @@ type (
 	symbol struct {
 		Symbol string `json:"symbol"`
 	}
+
+	// stampValue is a historical rate relayed with the stamps format
+	stampValue struct {
+		Rate      string `json:"rate"`
+		BlockNum  uint64 `json:"block_num,string"`
+		Timestamp int64  `json:"timestamp,string"`
+	}
 )
 
 func genRestartQueries(contractAddress, Denom string) ([]client.SmartQuery, error) {
@@ -116,7 +124,7 @@ func genRestartQueries(contractAddress, Denom string) ([]client.SmartQuery, erro
 
 func (r *Relayer) genWasmMsg(msgData []byte) *wasmtypes.MsgExecuteContract {
 	return &wasmtypes.MsgExecuteContract{
-		Sender:   r.relayerClient.RelayerAddrString,
+		Sender:   r.relayerClient.SenderAddress(),
 		Contract: r.contractAddress,
 		Msg:      msgData,
 		Funds:    nil,
@@ -142,31 +150,67 @@ func genRateMsgData(forceRelay bool, msgType MsgType, requestID uint64, resolveT
 		}
 
 		return
-	} else {
-		symbolRates := map[string][]string{}
-		for _, rate := range rates {
-			symbolRates[rate.Denom] = append(symbolRates[rate.Denom], rate.Amount.Mul(RateFactor).TruncateInt().String())
+	}
+
+	stamps := make([]PriceStamp, len(rates))
+	for i, rate := range rates {
+		stamps[i] = PriceStamp{Rate: rate}
+	}
+
+	return genHistoricalMsgData(forceRelay, msgType, requestID, resolveTime, stamps, StampFormatRates)
+}
+
+// genHistoricalMsgData generates the historical median or deviation msg, grouping the stamps by denom in denom order.
+// Values are relayed as rates, or as stamp objects with the block number and time for the stamps format.
+func genHistoricalMsgData(
+	forceRelay bool,
+	msgType MsgType,
+	requestID uint64,
+	resolveTime int64,
+	stamps []PriceStamp,
+	format StampFormat,
+) (msgData []byte, err error) {
+	msg := Msg{
+		SymbolRates: nil,
+		ResolveTime: resolveTime,
+		RequestID:   requestID,
+	}
+
+	// symbols are ordered by denom, and the values of each denom by block number
+	for _, stamp := range sortStamps(stamps) {
+		rate := stamp.Rate.Amount.Mul(RateFactor).TruncateInt().String()
+
+		var value interface{} = rate
+		if format == StampFormatStamps {
+			value = stampValue{Rate: rate, BlockNum: stamp.BlockNum, Timestamp: stamp.Timestamp}
 		}
 
-		for denom, medians := range symbolRates {
-			msg.SymbolRates = append(msg.SymbolRates, [2]interface{}{denom, medians})
+		last := len(msg.SymbolRates) - 1
+		if last < 0 || msg.SymbolRates[last][0] != stamp.Rate.Denom {
+			msg.SymbolRates = append(msg.SymbolRates, [2]interface{}{stamp.Rate.Denom, []interface{}{}})
+			last++
+		}
+
+		msg.SymbolRates[last][1] = append(msg.SymbolRates[last][1].([]interface{}), value)
+	}
+
+	switch msgType {
+	case RelayHistoricalMedian:
+		if forceRelay {
+			msgData, err = json.Marshal(MsgForceRelayHistoricalMedian{Relay: msg})
+		} else {
+			msgData, err = json.Marshal(MsgRelayHistoricalMedian{Relay: msg})
 		}
 
-		switch msgType {
-		case RelayHistoricalMedian:
-			if forceRelay {
-				msgData, err = json.Marshal(MsgForceRelayHistoricalMedian{Relay: msg})
-			} else {
-				msgData, err = json.Marshal(MsgRelayHistoricalMedian{Relay: msg})
-			}
-
-		case RelayHistoricalDeviation:
-			if forceRelay {
-				msgData, err = json.Marshal(MsgForceRelayHistoricalDeviation{Relay: msg})
-			} else {
-				msgData, err = json.Marshal(MsgRelayHistoricalDeviation{Relay: msg})
-			}
+	case RelayHistoricalDeviation:
+		if forceRelay {
+			msgData, err = json.Marshal(MsgForceRelayHistoricalDeviation{Relay: msg})
+		} else {
+			msgData, err = json.Marshal(MsgRelayHistoricalDeviation{Relay: msg})
 		}
+
+	default:
+		err = fmt.Errorf("unexpected historical msg type %d", msgType)
 	}
 
 	return
@@ -0,0 +1,158 @@
+package relayer
+
+import (
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+)
+
+const (
+	// OutlierDrop drops outlier rates from the relay.
+	OutlierDrop = "drop"
+	// OutlierClamp clamps outlier rates to the nearest bound of the median band.
+	OutlierClamp = "clamp"
+	// OutlierFlag relays outlier rates unchanged and raises an alert.
+	OutlierFlag = "flag"
+)
+
+// OutlierConfig defines the outlier filter of exchange rates. A rate is an outlier if it is
+// outside its latest ojo median plus or minus K times its latest median deviation.
+// The filter is disabled if K is not positive.
+type OutlierConfig struct {
+	K      types.Dec
+	Action string
+}
+
+// outlierDecision is the filter decision of a single rate.
+type outlierDecision struct {
+	rate      types.DecCoin
+	median    types.Dec
+	deviation types.Dec
+	outlier   bool
+	relayed   types.Dec
+}
+
+// enabled returns true if exchange rates are filtered.
+func (c OutlierConfig) enabled() bool {
+	return !c.K.IsNil() && c.K.IsPositive()
+}
+
+// filterOutliers applies the outlier filter to the rates using the latest historical medians
+// and deviations, and logs the decision of every rate.
+func (r *Relayer) filterOutliers(rates types.DecCoins) types.DecCoins {
+	if !r.outliers.enabled() {
+		return rates
+	}
+
+	filtered, decisions := filterOutliers(
+		rates,
+		latestStampRates(r.historicalMedians),
+		latestStampRates(r.historicalDeviations),
+		r.outliers,
+	)
+
+	outliers := 0
+	for _, decision := range decisions {
+		if !decision.outlier {
+			r.logger.Debug().
+				Str("denom", decision.rate.Denom).
+				Str("rate", decision.rate.Amount.String()).
+				Str("median", decision.median.String()).
+				Str("deviation", decision.deviation.String()).
+				Msg("rate within median band")
+			continue
+		}
+
+		outliers++
+		telemetry.IncrCounter(1, "outlier", r.outliers.Action)
+		logs := r.logger.Warn().
+			Str("denom", decision.rate.Denom).
+			Str("rate", decision.rate.Amount.String()).
+			Str("median", decision.median.String()).
+			Str("deviation", decision.deviation.String()).
+			Str("action", r.outliers.Action)
+		if !decision.relayed.IsNil() {
+			logs.Str("relayed rate", decision.relayed.String())
+		}
+
+		logs.Msg("rate outside median band")
+
+		r.alert(AlertOutlier, alert.SeverityWarning, "rate outside median band", map[string]string{
+			"denom":     decision.rate.Denom,
+			"rate":      decision.rate.Amount.String(),
+			"median":    decision.median.String(),
+			"deviation": decision.deviation.String(),
+			"action":    r.outliers.Action,
+		})
+	}
+
+	if outliers == 0 {
+		r.resolveAlert(AlertOutlier)
+	}
+
+	return filtered
+}
+
+// filterOutliers returns the rates filtered by the outlier action, and the decision of every rate
+// with a median and a deviation. Rates without a median or deviation are relayed unchanged.
+func filterOutliers(
+	rates types.DecCoins,
+	medians, deviations map[string]types.Dec,
+	config OutlierConfig,
+) (types.DecCoins, []outlierDecision) {
+	filtered := types.DecCoins{}
+	var decisions []outlierDecision
+	for _, rate := range rates {
+		median, foundMedian := medians[rate.Denom]
+		deviation, foundDeviation := deviations[rate.Denom]
+		if !foundMedian || !foundDeviation {
+			filtered = append(filtered, rate)
+			continue
+		}
+
+		band := deviation.Abs().Mul(config.K)
+		lower := types.MaxDec(median.Sub(band), types.ZeroDec())
+		upper := median.Add(band)
+
+		decision := outlierDecision{
+			rate:      rate,
+			median:    median,
+			deviation: deviation,
+			outlier:   rate.Amount.LT(lower) || rate.Amount.GT(upper),
+		}
+
+		switch {
+		case !decision.outlier, config.Action == OutlierFlag:
+			filtered = append(filtered, rate)
+			decision.relayed = rate.Amount
+
+		case config.Action == OutlierClamp:
+			clamped := types.MinDec(types.MaxDec(rate.Amount, lower), upper)
+			filtered = append(filtered, types.NewDecCoinFromDec(rate.Denom, clamped))
+			decision.relayed = clamped
+		}
+
+		// outliers are dropped by default
+		decisions = append(decisions, decision)
+	}
+
+	return filtered, decisions
+}
+
+// latestStampRates returns the rate of the stamp with the highest block number of each denom.
+func latestStampRates(stamps []PriceStamp) map[string]types.Dec {
+	latest := map[string]PriceStamp{}
+	for _, stamp := range stamps {
+		if current, found := latest[stamp.Rate.Denom]; !found || stamp.BlockNum >= current.BlockNum {
+			latest[stamp.Rate.Denom] = stamp
+		}
+	}
+
+	rates := make(map[string]types.Dec, len(latest))
+	for denom, stamp := range latest {
+		rates[denom] = stamp.Rate.Amount
+	}
+
+	return rates
+}
@@ -0,0 +1,91 @@
+package relayer
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	oracletypes "github.com/ojo-network/ojo/x/oracle/types"
+
+	"github.com/ojo-network/cw-relayer/relayer/client"
+)
+
+// oracleParams holds the ojo oracle params used to derive the median and deviation relay cadence,
+// and the symbol denoms of the ojo accept list.
+type oracleParams struct {
+	votePeriod        uint64
+	medianStampPeriod uint64
+	acceptList        []string
+	refreshed         time.Time
+}
+
+// paramsDue returns true if the ojo oracle params should be queried.
+func (r *Relayer) paramsDue(now time.Time) bool {
+	return (r.schedule.FromParams || r.completeness.AcceptList || r.syntheticPending) &&
+		(r.params.refreshed.IsZero() || !now.Before(r.params.refreshed.Add(r.schedule.ParamsRefresh)))
+}
+
+// refreshParams queries the ojo oracle params, and logs the drift of the relay cadence
+// if ojo governance changed the vote or median stamp period.
+func (r *Relayer) refreshParams(ctx context.Context) error {
+	var params oracletypes.Params
+	err := r.pool.Do(ctx, func(_ string, source client.PriceSource) error {
+		queryCtx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+		defer cancel()
+
+		var err error
+		params, err = source.Params(queryCtx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	acceptList := make([]string, len(params.AcceptList))
+	for i, denom := range params.AcceptList {
+		acceptList[i] = strings.ToUpper(denom.SymbolDenom)
+	}
+
+	previous := r.params
+	r.params = oracleParams{
+		votePeriod:        params.VotePeriod,
+		medianStampPeriod: params.MedianStampPeriod,
+		acceptList:        acceptList,
+		refreshed:         time.Now(),
+	}
+
+	switch {
+	case previous.refreshed.IsZero():
+		r.logger.Info().
+			Uint64("vote period", params.VotePeriod).
+			Uint64("median stamp period", params.MedianStampPeriod).
+			Int64("median duration", r.medianDuration).
+			Int64("derived median duration", r.params.ticks(r.skipNumEvents)).
+			Msg("relay cadence derived from ojo oracle params")
+
+	case previous.votePeriod != params.VotePeriod || previous.medianStampPeriod != params.MedianStampPeriod:
+		r.logger.Warn().
+			Uint64("vote period", params.VotePeriod).
+			Uint64("previous vote period", previous.votePeriod).
+			Uint64("median stamp period", params.MedianStampPeriod).
+			Uint64("previous median stamp period", previous.medianStampPeriod).
+			Msg("ojo oracle params changed, relay cadence updated")
+	}
+
+	return nil
+}
+
+// ticks returns the number of relayed ticks in a median stamp period,
+// every skipNumEvents-th tick is relayed.
+func (p oracleParams) ticks(skipNumEvents int64) int64 {
+	if p.votePeriod == 0 || skipNumEvents <= 0 {
+		return 1
+	}
+
+	ticks := int64(p.medianStampPeriod/p.votePeriod) / skipNumEvents
+	if ticks < 1 {
+		return 1
+	}
+
+	return ticks
+}
@@ -0,0 +1,360 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ojo-network/cw-relayer/relayer/client"
+)
+
+// QuorumConfig defines how many ojo endpoints must return matching prices before relaying,
+// quorum is disabled if Size is less than 2. Tolerance is the max relative difference
+// between two prices, prices must be equal if it is zero.
+type QuorumConfig struct {
+	Size      int
+	Tolerance types.Dec
+}
+
+// denomPrices holds the prices queried from ojo at a single height.
+type denomPrices struct {
+	height        int64
+	exchangeRates types.DecCoins
+	medians       []PriceStamp
+	deviations    []PriceStamp
+}
+
+// setDenomPrices queries exchange rates, medians and deviations from ojo. If eventRates is not empty,
+// it is used as the exchange rates and only medians and deviations are queried.
+// Queries are routed to the healthiest ojo endpoint and retried on other endpoints on failure.
+func (r *Relayer) setDenomPrices(
+	ctx context.Context,
+	postMedian, postDeviation bool,
+	eventRates types.DecCoins,
+	height int64,
+) error {
+	if !eventRates.Empty() && !postMedian && !postDeviation {
+		r.exchangeRates = eventRates
+		r.queryHeight = height
+		return nil
+	}
+
+	if r.quorum.Size > 1 {
+		return r.setQuorumDenomPrices(ctx, postMedian, postDeviation, eventRates, height)
+	}
+
+	var prices denomPrices
+	err := r.pool.Do(ctx, func(_ string, source client.PriceSource) error {
+		var err error
+		prices, err = r.queryDenomPrices(ctx, source, postMedian, postDeviation, eventRates, height)
+		return err
+	})
+
+	r.applyPrices(prices)
+	return err
+}
+
+// setQuorumDenomPrices queries prices from the configured number of ojo endpoints at the
+// same height, and sets the prices only if all the endpoints returned matching prices.
+// If an endpoint returned no medians or deviations, only the exchange rates are compared and
+// the data error is returned after setting them.
+func (r *Relayer) setQuorumDenomPrices(
+	ctx context.Context,
+	postMedian, postDeviation bool,
+	eventRates types.DecCoins,
+	height int64,
+) error {
+	if height <= 0 {
+		return fmt.Errorf("quorum requires an ojo height")
+	}
+
+	var (
+		mu      sync.Mutex
+		dataErr error
+	)
+	results := map[string]denomPrices{}
+	addresses, err := r.pool.Quorum(ctx, r.quorum.Size, func(address string, source client.PriceSource) error {
+		prices, err := r.queryDenomPrices(ctx, source, postMedian, postDeviation, eventRates, height)
+		if err != nil && !client.IsDataError(err) {
+			return err
+		}
+
+		mu.Lock()
+		results[address] = prices
+		if err != nil {
+			dataErr = err
+		}
+		mu.Unlock()
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(results) < r.quorum.Size {
+		telemetry.IncrCounter(1, "failure", "quorum")
+		return fmt.Errorf("quorum not reached, %d of %d endpoints responded", len(results), r.quorum.Size)
+	}
+
+	if dataErr != nil {
+		for address, prices := range results {
+			prices.medians = nil
+			prices.deviations = nil
+			results[address] = prices
+		}
+	}
+
+	if err := r.resolveQuorum(height, addresses, results); err != nil {
+		return err
+	}
+
+	return dataErr
+}
+
+// resolveQuorum sets the prices if the results of all endpoints match. Otherwise, the endpoints
+// disagreeing with a strict majority of the results are demoted, nobody is demoted on a tie.
+func (r *Relayer) resolveQuorum(height int64, addresses []string, results map[string]denomPrices) error {
+	// group endpoints returning matching prices
+	var groups [][]string
+	for _, address := range addresses {
+		prices, found := results[address]
+		if !found {
+			continue
+		}
+
+		matched := false
+		for i, group := range groups {
+			if prices.matches(results[group[0]], r.quorum.Tolerance) {
+				groups[i] = append(group, address)
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			groups = append(groups, []string{address})
+		}
+	}
+
+	if len(groups) == 1 {
+		r.applyPrices(results[groups[0][0]])
+		return nil
+	}
+
+	telemetry.IncrCounter(1, "failure", "quorum")
+
+	majority := 0
+	for i, group := range groups {
+		if len(group) > len(groups[majority]) {
+			majority = i
+		}
+	}
+
+	if 2*len(groups[majority]) <= len(results) {
+		r.logger.Error().
+			Int("groups", len(groups)).
+			Int64("ojo height", height).
+			Msg("endpoint prices disagree without a majority")
+		return fmt.Errorf("quorum not reached, endpoint prices disagree at height %d", height)
+	}
+
+	for i, group := range groups {
+		if i == majority {
+			continue
+		}
+
+		for _, address := range group {
+			r.logger.Error().
+				Str("endpoint", address).
+				Strs("majority", groups[majority]).
+				Int64("ojo height", height).
+				Msg("endpoint prices disagree with quorum")
+			r.pool.Demote(address)
+		}
+	}
+
+	return fmt.Errorf("quorum not reached, endpoint prices disagree at height %d", height)
+}
+
+// applyPrices sets the queried prices on the relayer,
+// empty prices of a failed query are not set.
+func (r *Relayer) applyPrices(prices denomPrices) {
+	if prices.exchangeRates.Empty() {
+		return
+	}
+
+	r.exchangeRates = prices.exchangeRates
+	r.queryHeight = prices.height
+
+	if prices.medians != nil {
+		r.historicalMedians = prices.medians
+	}
+
+	if prices.deviations != nil {
+		r.historicalDeviations = prices.deviations
+	}
+}
+
+// queryDenomPrices queries prices from a single ojo endpoint. All queries are pinned to
+// the given ojo height, or to the height of the exchange rates query if height is not positive.
+// The exchange rates are returned even if the median or deviation queries fail.
+func (r *Relayer) queryDenomPrices(
+	ctx context.Context,
+	source client.PriceSource,
+	postMedian, postDeviation bool,
+	eventRates types.DecCoins,
+	height int64,
+) (denomPrices, error) {
+	if eventRates.Empty() {
+		queryCtx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+		defer cancel()
+
+		exchangeRates, queryHeight, err := source.ExchangeRates(queryCtx, height)
+		if err != nil {
+			return denomPrices{}, err
+		}
+
+		// assuming an issue with rpc if exchange rates are empty
+		if exchangeRates.Empty() {
+			return denomPrices{}, noRates
+		}
+
+		if height > 0 && queryHeight != height {
+			return denomPrices{}, fmt.Errorf("query height %d does not match ojo height %d", queryHeight, height)
+		}
+
+		// pin medians and deviations to the exchange rates height
+		height = queryHeight
+		eventRates = exchangeRates
+	}
+
+	prices := denomPrices{
+		height:        height,
+		exchangeRates: eventRates,
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	g, _ := errgroup.WithContext(queryCtx)
+
+	if postDeviation {
+		g.Go(
+			func() error {
+				priceStamps, queryHeight, err := source.MedianDeviations(queryCtx, height)
+				if err != nil {
+					return err
+				}
+
+				if err := checkHeight(queryHeight, height); err != nil {
+					return err
+				}
+
+				deviations := r.window.apply(newPriceStamps(priceStamps))
+				if len(deviations) == 0 {
+					return noDeviations
+				}
+
+				if r.stampFormat == StampFormatStamps {
+					if err := r.setStampTimes(queryCtx, source, deviations); err != nil {
+						return err
+					}
+				}
+
+				mu.Lock()
+				prices.deviations = deviations
+				mu.Unlock()
+
+				return nil
+			},
+		)
+	}
+
+	if postMedian {
+		g.Go(
+			func() error {
+				priceStamps, queryHeight, err := source.Medians(queryCtx, height)
+				if err != nil {
+					return err
+				}
+
+				if err := checkHeight(queryHeight, height); err != nil {
+					return err
+				}
+
+				medians := r.window.apply(newPriceStamps(priceStamps))
+				if len(medians) == 0 {
+					return noMedians
+				}
+
+				if r.stampFormat == StampFormatStamps {
+					if err := r.setStampTimes(queryCtx, source, medians); err != nil {
+						return err
+					}
+				}
+
+				mu.Lock()
+				prices.medians = medians
+				mu.Unlock()
+
+				return nil
+			},
+		)
+	}
+
+	err := g.Wait()
+	return prices, err
+}
+
+// matches returns true if the prices were queried at the same height and
+// all rates are within the relative tolerance of each other.
+func (p denomPrices) matches(other denomPrices, tolerance types.Dec) bool {
+	return p.height == other.height &&
+		ratesMatch(p.exchangeRates, other.exchangeRates, tolerance) &&
+		stampsMatch(p.medians, other.medians, tolerance) &&
+		stampsMatch(p.deviations, other.deviations, tolerance)
+}
+
+// ratesMatch returns true if both lists have the same denoms in the same order,
+// and amounts within the relative tolerance of each other.
+func ratesMatch(a, b types.DecCoins, tolerance types.Dec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Denom != b[i].Denom {
+			return false
+		}
+
+		if tolerance.IsNil() || tolerance.IsZero() {
+			if !a[i].Amount.Equal(b[i].Amount) {
+				return false
+			}
+
+			continue
+		}
+
+		diff := a[i].Amount.Sub(b[i].Amount).Abs()
+		if diff.GT(types.MaxDec(a[i].Amount.Abs(), b[i].Amount.Abs()).Mul(tolerance)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkHeight returns an error if the query was not served at the given height.
+func checkHeight(queryHeight, height int64) error {
+	if queryHeight != height {
+		return fmt.Errorf("query height %d does not match ojo height %d", queryHeight, height)
+	}
+
+	return nil
+}
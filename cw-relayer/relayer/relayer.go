@@ -5,28 +5,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/telemetry"
 	"github.com/cosmos/cosmos-sdk/types"
-	oracletypes "github.com/ojo-network/ojo/x/oracle/types"
 	"github.com/rs/zerolog"
-	"golang.org/x/sync/errgroup"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/ojo-network/cw-relayer/pkg/alert"
 	psync "github.com/ojo-network/cw-relayer/pkg/sync"
 	"github.com/ojo-network/cw-relayer/relayer/client"
-	"github.com/ojo-network/cw-relayer/tools"
 )
 
 var (
 	// RateFactor is used to convert ojo prices to contract-compatible values.
 	RateFactor   = types.NewDec(10).Power(9)
 	noRates      = fmt.Errorf("no rates found")
-	noMedians    = fmt.Errorf("median deviations empty")
-	noDeviations = fmt.Errorf("deviation deviations empty")
+	noMedians    = client.NewDataError("median deviations empty")
+	noDeviations = client.NewDataError("deviation deviations empty")
 )
 
 // Relayer defines a structure that queries prices from ojo and publishes prices to wasm contract.
@@ -35,33 +31,62 @@ type Relayer struct {
 	closer *psync.Closer
 
 	relayerClient      client.RelayerClient
-	queryRPCS          []string
+	pool               *client.EndpointPool
 	contractAddress    string
 	requestID          uint64
 	medianRequestID    uint64
 	deviationRequestID uint64
 
 	exchangeRates        types.DecCoins
-	historicalMedians    types.DecCoins
-	historicalDeviations types.DecCoins
+	historicalMedians    []PriceStamp
+	historicalDeviations []PriceStamp
 	resolveDuration      time.Duration
 	queryTimeout         time.Duration
 
+	// ojo block height of the queried prices and of the last relayed prices
+	queryHeight       int64
+	lastRelayedHeight int64
+
+	// schedule time and ojo height of the last successful median and deviation relays
+	lastMedianRelay     time.Time
+	lastDeviationRelay  time.Time
+	lastMedianHeight    int64
+	lastDeviationHeight int64
+
 	// if missedCounter >= missedThreshold, force relay prices (bypasses timing restrictions)
 	missedCounter     int64
 	missedThreshold   int64
 	timeoutHeight     int64
 	medianDuration    int64
 	deviationDuration int64
-	maxQueryRetries   int64
-	queryRetries      int64
 	skipNumEvents     int64
-	index             int
 
 	ignoreMedianErrors bool
-
-	event  chan struct{}
-	config AutoRestartConfig
+	eventRates         bool
+	quorum             QuorumConfig
+	stampFormat        StampFormat
+	window             HistoryWindow
+	schedule           ScheduleConfig
+	params             oracleParams
+	completeness       CompletenessConfig
+	staleness          StalenessConfig
+	stale              staleness
+	outliers           OutlierConfig
+	derived            DerivedConfig
+	history            *rateHistory
+	synthetic          []SyntheticSymbol
+	syntheticPending   bool
+	verify             VerifyConfig
+	verifying          chan struct{}
+	alerts             AlertConfig
+	balance            BalanceConfig
+	feeGrant           FeeGrantConfig
+	feeGrantCheck      chan struct{}
+	blockTimes         *blockTimeCache
+
+	event    chan client.Tick
+	config   AutoRestartConfig
+	profiles map[string]RelayProfile
 }
 
 type AutoRestartConfig struct {
@@ -70,47 +95,100 @@ type AutoRestartConfig struct {
 	SkipError   bool
 }
 
-// New returns an instance of the relayer.
+// RelayProfile defines the denoms relayed on ticks mapped to the profile,
+// all denoms are relayed if Denoms is empty.
+type RelayProfile struct {
+	Name   string
+	Denoms []string
+}
+
+// Config defines the relay settings of the relayer.
+type Config struct {
+	ContractAddress    string
+	TimeoutHeight      int64
+	MissedThreshold    int64
+	MedianDuration     int64
+	DeviationDuration  int64
+	SkipNumEvents      int64
+	IgnoreMedianErrors bool
+	EventRates         bool
+	ResolveDuration    time.Duration
+	QueryTimeout       time.Duration
+
+	// request ids used at startup, unless restored by AutoRestart
+	RequestID          uint64
+	MedianRequestID    uint64
+	DeviationRequestID uint64
+
+	AutoRestart  AutoRestartConfig
+	Profiles     []RelayProfile
+	Quorum       QuorumConfig
+	StampFormat  StampFormat
+	Window       HistoryWindow
+	Schedule     ScheduleConfig
+	Completeness CompletenessConfig
+	Staleness    StalenessConfig
+	Outliers     OutlierConfig
+	Derived      DerivedConfig
+	Synthetic    []SyntheticSymbol
+	Verify       VerifyConfig
+	Alerts       AlertConfig
+	Balance      BalanceConfig
+	FeeGrant     FeeGrantConfig
+}
+
+// New returns an instance of the relayer relaying the ticks of the event channel,
+// with prices queried from the endpoint pool.
 func New(
 	logger zerolog.Logger,
 	oc client.RelayerClient,
-	contractAddress string,
-	timeoutHeight int64,
-	missedThreshold int64,
-	maxQueryRetries int64,
-	medianDuration int64,
-	deviationDuration int64,
-	skipNumEvents int64,
-	ignoreMedianErrors bool,
-	resolveDuration time.Duration,
-	queryTimeout time.Duration,
-	requestID uint64,
-	medianRequestID uint64,
-	deviationRequestID uint64,
-	config AutoRestartConfig,
-	event chan struct{},
-	queryRPCS []string,
+	pool *client.EndpointPool,
+	event chan client.Tick,
+	cfg Config,
 ) *Relayer {
+	profileMap := make(map[string]RelayProfile, len(cfg.Profiles))
+	for _, profile := range cfg.Profiles {
+		profileMap[profile.Name] = profile
+	}
+
 	return &Relayer{
-		queryRPCS:          queryRPCS,
+		pool:               pool,
 		logger:             logger.With().Str("module", "relayer").Logger(),
 		relayerClient:      oc,
-		contractAddress:    contractAddress,
-		missedThreshold:    missedThreshold,
-		timeoutHeight:      timeoutHeight,
-		queryTimeout:       queryTimeout,
-		medianDuration:     medianDuration,
-		deviationDuration:  deviationDuration,
-		ignoreMedianErrors: ignoreMedianErrors,
-		resolveDuration:    resolveDuration,
-		requestID:          requestID,
-		medianRequestID:    medianRequestID,
-		deviationRequestID: deviationRequestID,
-		maxQueryRetries:    maxQueryRetries,
-		skipNumEvents:      skipNumEvents,
+		contractAddress:    cfg.ContractAddress,
+		missedThreshold:    cfg.MissedThreshold,
+		timeoutHeight:      cfg.TimeoutHeight,
+		queryTimeout:       cfg.QueryTimeout,
+		medianDuration:     cfg.MedianDuration,
+		deviationDuration:  cfg.DeviationDuration,
+		ignoreMedianErrors: cfg.IgnoreMedianErrors,
+		eventRates:         cfg.EventRates,
+		resolveDuration:    cfg.ResolveDuration,
+		requestID:          cfg.RequestID,
+		medianRequestID:    cfg.MedianRequestID,
+		deviationRequestID: cfg.DeviationRequestID,
+		skipNumEvents:      cfg.SkipNumEvents,
 		closer:             psync.NewCloser(),
 		event:              event,
-		config:             config,
+		config:             cfg.AutoRestart,
+		profiles:           profileMap,
+		quorum:             cfg.Quorum,
+		stampFormat:        cfg.StampFormat,
+		window:             cfg.Window,
+		schedule:           cfg.Schedule,
+		completeness:       cfg.Completeness,
+		staleness:          cfg.Staleness,
+		outliers:           cfg.Outliers,
+		derived:            cfg.Derived,
+		history:            newRateHistory(cfg.Derived.Series),
+		synthetic:          cfg.Synthetic,
+		verify:             cfg.Verify,
+		verifying:          make(chan struct{}, maxVerifications),
+		alerts:             cfg.Alerts,
+		balance:            cfg.Balance,
+		feeGrant:           cfg.FeeGrant,
+		feeGrantCheck:      make(chan struct{}, 1),
+		blockTimes:         newBlockTimeCache(),
 	}
 }
 
@@ -133,6 +211,32 @@ func (r *Relayer) Start(ctx context.Context) error {
 			Uint64("deviation request id", r.deviationRequestID).Msg("relayer state startup successful")
 	}
 
+	if err := r.checkSyntheticDenoms(ctx); err != nil {
+		return err
+	}
+
+	if r.relayerClient.HasFeeGranter() {
+		if err := r.checkFeeGrant(ctx); err != nil {
+			if !r.feeGrant.Fallback {
+				return fmt.Errorf("fee grant check failed: %w", err)
+			}
+
+			r.logger.Err(err).Msg("fee grant check failed")
+		}
+
+		go r.monitorFeeGrant(ctx)
+	}
+
+	if len(r.balance.Denom) > 0 {
+		go r.monitorBalance(ctx)
+	}
+
+	if len(r.derived.Series) > 0 && r.derived.Warmup {
+		if err := r.warmUp(ctx); err != nil {
+			r.logger.Err(err).Msg("error warming up derived series, warming up from relayed rates")
+		}
+	}
+
 	epoch := int64(-1)
 	skipEvents := r.skipNumEvents > 0
 	r.skipNumEvents++
@@ -141,7 +245,7 @@ func (r *Relayer) Start(ctx context.Context) error {
 		case <-ctx.Done():
 			r.closer.Close()
 
-		case <-r.event:
+		case tick := <-r.event:
 			epoch++
 			if skipEvents {
 				if epoch%r.skipNumEvents != 0 {
@@ -150,9 +254,9 @@ func (r *Relayer) Start(ctx context.Context) error {
 				}
 			}
 
-			r.logger.Debug().Msg("relayer tick")
+			r.logger.Debug().Strs("profiles", tick.Profiles).Msg("relayer tick")
 			startTime := time.Now()
-			if err := r.tick(ctx); err != nil {
+			if err := r.tick(ctx, tick); err != nil {
 				telemetry.IncrCounter(1, "failure", "tick")
 				r.logger.Err(err).Msg("relayer tick failed")
 			}
@@ -169,13 +273,6 @@ func (r *Relayer) Stop() {
 	<-r.closer.Done()
 }
 
-// incrementIndex increases index to switch to different query rpc
-func (r *Relayer) increment() {
-	r.queryRetries += 1
-	r.index = (r.index + 1) % len(r.queryRPCS)
-	r.logger.Info().Int("rpc index", r.index).Msg("switching query rpc")
-}
-
 // restart queries wasmd chain to fetch latest request, median request and deviation request id
 func (r *Relayer) restart(ctx context.Context) error {
 	queryMsgs, err := genRestartQueries(r.contractAddress, r.config.Denom)
@@ -217,104 +314,14 @@ func (r *Relayer) restart(ctx context.Context) error {
 	return nil
 }
 
-func (r *Relayer) setDenomPrices(ctx context.Context, postMedian, postDeviation bool) error {
-	if r.queryRetries > r.maxQueryRetries {
-		r.queryRetries = 0
-		return noRates
-	}
-
-	grpcConn, err := grpc.Dial(
-		r.queryRPCS[r.index],
-		// the Cosmos SDK doesn't support any transport security mechanism
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithContextDialer(tools.DialerFunc),
-	)
-
-	// retry or switch rpc
-	if err != nil {
-		r.increment()
-		return r.setDenomPrices(ctx, postMedian, postDeviation)
-	}
-
-	defer grpcConn.Close()
-
-	queryClient := oracletypes.NewQueryClient(grpcConn)
-
-	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
-	defer cancel()
-
-	queryResponse, err := queryClient.ExchangeRates(ctx, &oracletypes.QueryExchangeRates{})
-	// assuming an issue with rpc if exchange rates are empty
-	if err != nil || queryResponse.ExchangeRates.Empty() {
-		r.logger.Debug().Msg("error querying exchange rates")
-		r.increment()
-		return r.setDenomPrices(ctx, postMedian, postDeviation)
-	}
-
-	r.exchangeRates = queryResponse.ExchangeRates
-
-	var mu sync.Mutex
-	g, _ := errgroup.WithContext(ctx)
-
-	if postDeviation {
-		g.Go(
-			func() error {
-				deviationsQueryResponse, err := queryClient.MedianDeviations(ctx, &oracletypes.QueryMedianDeviations{})
-				if err != nil {
-					return err
-				}
-
-				if len(deviationsQueryResponse.MedianDeviations) == 0 {
-					return noDeviations
-				}
-
-				deviations := make([]types.DecCoin, len(deviationsQueryResponse.MedianDeviations))
-				for i, priceStamp := range deviationsQueryResponse.MedianDeviations {
-					deviations[i] = *priceStamp.ExchangeRate
-				}
-
-				mu.Lock()
-				r.historicalDeviations = deviations
-				mu.Unlock()
-
-				return nil
-			},
-		)
-	}
-
-	if postMedian {
-		g.Go(
-			func() error {
-				medianQueryResponse, err := queryClient.Medians(ctx, &oracletypes.QueryMedians{})
-				if err != nil {
-					return err
-				}
-
-				if len(medianQueryResponse.Medians) == 0 {
-					return noMedians
-				}
-
-				medians := make([]types.DecCoin, len(medianQueryResponse.Medians))
-				for i, priceStamp := range medianQueryResponse.Medians {
-					medians[i] = *priceStamp.ExchangeRate
-				}
-
-				mu.Lock()
-				r.historicalMedians = medians
-				mu.Unlock()
-
-				return nil
-			},
-		)
-	}
-
-	return g.Wait()
-}
-
 // tick queries price from ojo and broadcasts wasm tx with prices to the wasm contract periodically.
-func (r *Relayer) tick(ctx context.Context) error {
+func (r *Relayer) tick(ctx context.Context, tick client.Tick) error {
 	r.logger.Debug().Msg("executing relayer tick")
 
+	r.checkSwitchStorm()
+
+	profile := r.mergeProfiles(tick.Profiles)
+
 	blockHeight, err := r.relayerClient.ChainHeight.GetChainHeight()
 	if err != nil {
 		return err
@@ -332,22 +339,40 @@ func (r *Relayer) tick(ctx context.Context) error {
 		return fmt.Errorf("expected positive blocktimestamp")
 	}
 
-	var postMedian bool
-	if r.medianDuration > 0 {
-		postMedian = r.requestID%uint64(r.medianDuration) == 0
+	if r.paramsDue(time.Now()) {
+		if err := r.refreshParams(ctx); err != nil {
+			r.logger.Err(err).Msg("error querying ojo oracle params")
+		}
 	}
 
-	var postDeviation bool
-	if r.deviationDuration > 0 {
-		postDeviation = r.requestID%uint64(r.deviationDuration) == 0
+	if err := r.checkPendingSyntheticDenoms(); err != nil {
+		return err
 	}
 
-	err = r.setDenomPrices(ctx, postMedian, postDeviation)
+	scheduleTime := r.schedule.now(blockTimestamp)
+	postMedian, postDeviation := r.historicalDue(scheduleTime, tick.Height)
+
+	var eventRates types.DecCoins
+	if r.eventRates {
+		eventRates, err = decodeEventRates(tick.Events)
+		if err != nil {
+			r.logger.Err(err).Msg("error decoding event rates, querying exchange rates")
+			eventRates = nil
+		}
+	}
+
+	// medians and deviations are queried on every tick for the outlier filter
+	queryMedian := postMedian || r.outliers.enabled()
+	queryDeviation := postDeviation || r.outliers.enabled()
+
+	err = r.setDenomPrices(ctx, queryMedian, queryDeviation, eventRates, tick.Height)
 	switch err {
 	case nil:
-		break
+		if r.outliers.enabled() {
+			r.resolveAlert(AlertOutlierMedians)
+		}
 	case noMedians, noDeviations:
-		if !r.ignoreMedianErrors {
+		if err := r.checkMedianError(err, postMedian || postDeviation, tick.Height); err != nil {
 			return err
 		}
 
@@ -358,12 +383,38 @@ func (r *Relayer) tick(ctx context.Context) error {
 		return err
 	}
 
+	if err := r.checkRelayHeight(); err != nil {
+		return err
+	}
+
+	if err := r.checkStaleness(ctx); err != nil {
+		return err
+	}
+
 	nextBlockHeight := blockHeight + 1
 	forceRelay := r.missedCounter >= r.missedThreshold
 
 	// set the next resolve time for price feeds on wasm contract
 	nextBlockTime := blockTimestamp.Add(r.resolveDuration).Unix()
-	exchangeMsg, err := genRateMsgData(forceRelay, RelayRate, r.requestID, nextBlockTime, r.exchangeRates)
+	rates := r.filterOutliers(r.exchangeRates)
+	rates = append(rates, r.syntheticRates(rates)...)
+	sampleTime, sampled := r.sampleRates(ctx, rates)
+
+	exchangeRates := profile.filter(rates)
+	if len(exchangeRates) == 0 {
+		r.logger.Warn().Str("profile", profile.Name).Msg("no rates of the relay profile, skipping relay")
+		return nil
+	}
+
+	if err := r.checkCompleteness(profile, exchangeRates); err != nil {
+		return err
+	}
+
+	if sampled {
+		exchangeRates = append(exchangeRates, r.derivedRates(sampleTime, exchangeRates)...)
+	}
+
+	exchangeMsg, err := genRateMsgData(forceRelay, RelayRate, r.requestID, nextBlockTime, exchangeRates)
 	if err != nil {
 		return err
 	}
@@ -372,6 +423,8 @@ func (r *Relayer) tick(ctx context.Context) error {
 	logs.Str("contract address", r.contractAddress).
 		Str("relayer address", r.relayerClient.RelayerAddrString).
 		Str("block timestamp", blockTimestamp.String()).
+		Str("profile", profile.Name).
+		Int64("ojo height", r.queryHeight).
 		Bool("median posted", postMedian).
 		Bool("deviation posted", postDeviation).
 		Uint64("request id", r.requestID)
@@ -379,15 +432,16 @@ func (r *Relayer) tick(ctx context.Context) error {
 	var msgs []types.Msg
 	msgs = append(msgs, r.genWasmMsg(exchangeMsg))
 
+	nextMedianBlockTime, nextDeviationBlockTime := r.historicalResolveTimes(blockTimestamp)
+
 	if postDeviation {
-		resolveTime := time.Duration(r.resolveDuration.Nanoseconds() * r.deviationDuration)
-		nextDeviationBlockTime := blockTimestamp.Add(resolveTime).Unix()
-		deviationMsg, err := genRateMsgData(
+		deviationMsg, err := genHistoricalMsgData(
 			forceRelay,
 			RelayHistoricalDeviation,
 			r.deviationRequestID,
 			nextDeviationBlockTime,
-			r.historicalDeviations,
+			profile.filterStamps(r.historicalDeviations),
+			r.stampFormat,
 		)
 		if err != nil {
 			return err
@@ -398,14 +452,13 @@ func (r *Relayer) tick(ctx context.Context) error {
 	}
 
 	if postMedian {
-		resolveTime := time.Duration(r.resolveDuration.Nanoseconds() * r.medianDuration)
-		nextMedianBlockTime := blockTimestamp.Add(resolveTime).Unix()
-		medianMsg, err := genRateMsgData(
+		medianMsg, err := genHistoricalMsgData(
 			forceRelay,
 			RelayHistoricalMedian,
 			r.medianRequestID,
 			nextMedianBlockTime,
-			r.historicalMedians,
+			profile.filterStamps(r.historicalMedians),
+			r.stampFormat,
 		)
 		if err != nil {
 			return err
@@ -416,11 +469,30 @@ func (r *Relayer) tick(ctx context.Context) error {
 	}
 
 	logs.Msg("broadcasting execute to contract")
-	if err := r.relayerClient.BroadcastTx(r.resolveDuration, nextBlockHeight, r.timeoutHeight, msgs...); err != nil {
+	resp, err := r.relayerClient.BroadcastTx(r.resolveDuration, nextBlockHeight, r.timeoutHeight, msgs...)
+	if err != nil {
+		r.requestFeeGrantCheck(err)
 		r.missedCounter += 1
+		if r.missedCounter == r.missedThreshold {
+			r.alert(AlertMissedThreshold, alert.SeverityCritical, "relay missed threshold reached", map[string]string{
+				"missed": strconv.FormatInt(r.missedCounter, 10),
+				"error":  err.Error(),
+			})
+		}
+
 		return err
 	}
 
+	if r.missedCounter >= r.missedThreshold {
+		r.resolveAlert(AlertMissedThreshold)
+	}
+
+	if r.verify.Enabled {
+		r.startVerify(ctx, resp.TxHash, r.requestID, nextBlockTime, exchangeRates)
+	}
+
+	r.lastRelayedHeight = r.queryHeight
+
 	// reset missed counter if force relay is successful
 	if forceRelay {
 		r.missedCounter = 0
@@ -430,11 +502,116 @@ func (r *Relayer) tick(ctx context.Context) error {
 	r.requestID += 1
 	if postMedian {
 		r.medianRequestID += 1
+		r.lastMedianRelay = scheduleTime
+		r.lastMedianHeight = r.queryHeight
 	}
 
 	if postDeviation {
 		r.deviationRequestID += 1
+		r.lastDeviationRelay = scheduleTime
+		r.lastDeviationHeight = r.queryHeight
+	}
+
+	return nil
+}
+
+// checkMedianError returns the median or deviation error of the tick if historical values are
+// posted, or if the outlier filter is enabled, unless median errors are ignored. If the error is
+// ignored, the outlier filter uses the medians and deviations of a previous tick and an alert is raised.
+func (r *Relayer) checkMedianError(err error, postHistorical bool, height int64) error {
+	if !r.ignoreMedianErrors && (postHistorical || r.outliers.enabled()) {
+		return err
+	}
+
+	if r.outliers.enabled() {
+		r.logger.Warn().Err(err).Int64("ojo height", height).Msg("outlier filter uses the medians and deviations of a previous tick")
+		r.alert(AlertOutlierMedians, alert.SeverityWarning, "outlier filter uses stale medians", map[string]string{
+			"error":      err.Error(),
+			"ojo height": strconv.FormatInt(height, 10),
+		})
 	}
 
 	return nil
 }
+
+// checkRelayHeight returns an error if the queried prices are not newer than the last relayed
+// prices, so that prices of an ojo height are relayed once.
+func (r *Relayer) checkRelayHeight() error {
+	if r.queryHeight <= r.lastRelayedHeight {
+		return fmt.Errorf("ojo height %d is not newer than last relayed height %d", r.queryHeight, r.lastRelayedHeight)
+	}
+
+	return nil
+}
+
+// mergeProfiles returns the profile relaying the denoms of all the profiles, so that the profiles
+// of a tick share one relay and one median and deviation cadence. All denoms are relayed if any
+// of the profiles relays all denoms.
+func (r *Relayer) mergeProfiles(names []string) RelayProfile {
+	merged := RelayProfile{Name: strings.Join(names, ",")}
+	seen := map[string]bool{}
+	for _, name := range names {
+		profile := r.profiles[name]
+		if len(profile.Denoms) == 0 {
+			merged.Denoms = nil
+			return merged
+		}
+
+		for _, denom := range profile.Denoms {
+			if !seen[denom] {
+				seen[denom] = true
+				merged.Denoms = append(merged.Denoms, denom)
+			}
+		}
+	}
+
+	return merged
+}
+
+// filter returns the rates of the denoms in the profile.
+func (p RelayProfile) filter(rates types.DecCoins) types.DecCoins {
+	if len(p.Denoms) == 0 {
+		return rates
+	}
+
+	filtered := types.DecCoins{}
+	for _, rate := range rates {
+		if containsDenom(p.Denoms, rate.Denom) {
+			filtered = append(filtered, rate)
+		}
+	}
+
+	return filtered
+}
+
+// filterStamps returns the stamps of the denoms in the profile.
+func (p RelayProfile) filterStamps(stamps []PriceStamp) []PriceStamp {
+	if len(p.Denoms) == 0 {
+		return stamps
+	}
+
+	filtered := []PriceStamp{}
+	for _, stamp := range stamps {
+		if containsDenom(p.Denoms, stamp.Rate.Denom) {
+			filtered = append(filtered, stamp)
+		}
+	}
+
+	return filtered
+}
+
+// filterDenoms returns the denoms in the profile.
+func (p RelayProfile) filterDenoms(denoms []string) []string {
+	if len(p.Denoms) == 0 {
+		return denoms
+	}
+
+	var filtered []string
+	for _, denom := range denoms {
+		if containsDenom(p.Denoms, denom) {
+			filtered = append(filtered, denom)
+		}
+	}
+
+	return filtered
+}
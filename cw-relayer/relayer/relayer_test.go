@@ -1,17 +1,30 @@
 package relayer
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"testing"
 	"time"
 
+	abcitypes "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	oracletypes "github.com/ojo-network/ojo/x/oracle/types"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+	"github.com/ojo-network/cw-relayer/pkg/endpoint"
 	"github.com/ojo-network/cw-relayer/relayer/client"
 )
 
@@ -21,28 +34,15 @@ type RelayerTestSuite struct {
 }
 
 func (rts *RelayerTestSuite) SetupSuite() {
-	rts.relayer = New(
-		zerolog.Nop(),
-		client.RelayerClient{},
-		"",
-		100,
-		5,
-		10,
-		0,
-		0,
-		2,
-		true,
-		1*time.Second,
-		1*time.Second,
-		0,
-		0,
-		0,
-		AutoRestartConfig{
-			AutoRestart: false,
-			Denom:       "",
-			SkipError:   false,
-		}, nil, []string{""},
-	)
+	rts.relayer = New(zerolog.Nop(), client.RelayerClient{}, nil, nil, Config{
+		TimeoutHeight:      100,
+		MissedThreshold:    5,
+		SkipNumEvents:      2,
+		IgnoreMedianErrors: true,
+		ResolveDuration:    1 * time.Second,
+		QueryTimeout:       1 * time.Second,
+		StampFormat:        StampFormatRates,
+	})
 }
 
 func TestServiceTestSuite(t *testing.T) {
@@ -171,9 +171,720 @@ func (rts *RelayerTestSuite) Test_generateMedianRelayMsg() {
 			rates := expectedMsg[key].SymbolRates
 			rts.Require().Len(rates, 3)
 
-			for _, rate := range rates {
-				rts.Require().Equal(rate[1], rateMap[rate[0].(string)])
+			for i, denom := range []string{"atom", "juno", "umee"} {
+				rts.Require().Equal(denom, rates[i][0])
+				rts.Require().Equal(rates[i][1], rateMap[denom])
 			}
 		})
 	}
 }
+
+func (rts *RelayerTestSuite) Test_generateStampRelayMsg() {
+	stamps := []PriceStamp{
+		{Rate: types.NewDecCoinFromDec("atom", types.MustNewDecFromStr("1.2")), BlockNum: 20, Timestamp: 1200},
+		{Rate: types.NewDecCoinFromDec("atom", types.MustNewDecFromStr("1.1")), BlockNum: 10, Timestamp: 1100},
+	}
+
+	msg, err := genHistoricalMsgData(false, RelayHistoricalMedian, 0, 0, stamps, StampFormatStamps)
+	rts.Require().NoError(err)
+	rts.Require().JSONEq(
+		`{"relay_historical_median":{"symbol_rates":[["atom",[`+
+			`{"rate":"1100000000","block_num":"10","timestamp":"1100"},`+
+			`{"rate":"1200000000","block_num":"20","timestamp":"1200"}]]],`+
+			`"resolve_time":"0","request_id":"0"}}`,
+		string(msg),
+	)
+
+	// rates are ordered by block number
+	msg, err = genHistoricalMsgData(false, RelayHistoricalMedian, 0, 0, stamps, StampFormatRates)
+	rts.Require().NoError(err)
+	rts.Require().JSONEq(
+		`{"relay_historical_median":{"symbol_rates":[["atom",["1100000000","1200000000"]]],`+
+			`"resolve_time":"0","request_id":"0"}}`,
+		string(msg),
+	)
+}
+
+func (rts *RelayerTestSuite) Test_historyWindow() {
+	var stamps []PriceStamp
+	for _, denom := range []string{"umee", "atom"} {
+		for _, blockNum := range []uint64{30, 10, 20} {
+			stamps = append(stamps, PriceStamp{Rate: types.NewDecCoin(denom, types.NewInt(1)), BlockNum: blockNum})
+		}
+	}
+
+	split := func(stamps []PriceStamp) (denoms []string, blockNums []uint64) {
+		for _, stamp := range stamps {
+			denoms = append(denoms, stamp.Rate.Denom)
+			blockNums = append(blockNums, stamp.BlockNum)
+		}
+
+		return
+	}
+
+	denoms, nums := split(HistoryWindow{}.apply(stamps))
+	rts.Require().Equal([]string{"atom", "atom", "atom", "umee", "umee", "umee"}, denoms)
+	rts.Require().Equal([]uint64{10, 20, 30, 10, 20, 30}, nums)
+
+	denoms, nums = split(HistoryWindow{Size: 2, Denoms: map[string]int{"umee": 1}}.apply(stamps))
+	rts.Require().Equal([]string{"atom", "atom", "umee"}, denoms)
+	rts.Require().Equal([]uint64{20, 30, 30}, nums)
+}
+
+func (rts *RelayerTestSuite) Test_due() {
+	now := time.Unix(1000, 0)
+
+	// interval schedules are due once the interval passed since the last relay
+	rts.Require().True(due(time.Minute, 0, time.Time{}, now, 1))
+	rts.Require().False(due(time.Minute, 0, now.Add(-30*time.Second), now, 1))
+	rts.Require().True(due(time.Minute, 0, now.Add(-time.Minute), now, 1))
+
+	// request id durations are used without an interval
+	rts.Require().True(due(0, 2, time.Time{}, now, 4))
+	rts.Require().False(due(0, 2, time.Time{}, now, 5))
+	rts.Require().False(due(0, 0, time.Time{}, now, 4))
+
+	// ojo param schedules are due once a median stamp period passed since the last relay height
+	rts.Require().True(dueHeight(10, 0, 5))
+	rts.Require().False(dueHeight(10, 100, 109))
+	rts.Require().True(dueHeight(10, 100, 110))
+
+	// relayed ticks per median stamp period
+	params := oracleParams{votePeriod: 5, medianStampPeriod: 100}
+	rts.Require().Equal(int64(20), params.ticks(1))
+	rts.Require().Equal(int64(10), params.ticks(2))
+	rts.Require().Equal(int64(1), oracleParams{}.ticks(1))
+}
+
+func (rts *RelayerTestSuite) Test_missingDenoms() {
+	rates := types.DecCoins{
+		types.NewDecCoin("ATOM", types.NewInt(1)),
+		types.NewDecCoin("OJO", types.NewInt(1)),
+	}
+
+	rts.Require().Empty(missingDenoms([]string{"atom", "OJO"}, rates))
+	rts.Require().Equal([]string{"JUNO"}, missingDenoms([]string{"ATOM", "juno", "JUNO"}, rates))
+
+	profile := RelayProfile{Denoms: []string{"ATOM"}}
+	rts.Require().Equal([]string{"atom"}, profile.filterDenoms([]string{"atom", "juno"}))
+}
+
+func (rts *RelayerTestSuite) Test_staleness() {
+	rates := types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(1))}
+	changed := types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(2))}
+
+	var s staleness
+	rts.Require().Equal(int64(0), s.observe(10, rates))
+	rts.Require().Equal(int64(1), s.observe(11, rates))
+	rts.Require().Equal(int64(2), s.observe(12, rates))
+	rts.Require().Equal(int64(0), s.observe(13, changed))
+
+	// repeated ticks at the same ojo height are observed once
+	rts.Require().Equal(int64(0), s.observe(13, changed))
+	rts.Require().Equal(int64(0), s.observe(13, changed))
+	rts.Require().Equal(int64(1), s.observe(14, changed))
+
+	// ticks without an ojo height are always observed
+	rts.Require().Equal(int64(2), s.observe(0, changed))
+	rts.Require().Equal(int64(3), s.observe(0, changed))
+}
+
+func (rts *RelayerTestSuite) Test_filterOutliers() {
+	rates := types.DecCoins{
+		types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("10.5")),
+		types.NewDecCoinFromDec("JUNO", types.MustNewDecFromStr("14")),
+		types.NewDecCoinFromDec("OJO", types.MustNewDecFromStr("1")),
+	}
+
+	medians := latestStampRates([]PriceStamp{
+		{Rate: types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("1")), BlockNum: 1},
+		{Rate: types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("10")), BlockNum: 2},
+		{Rate: types.NewDecCoinFromDec("JUNO", types.MustNewDecFromStr("10")), BlockNum: 2},
+	})
+	deviations := latestStampRates([]PriceStamp{
+		{Rate: types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("1")), BlockNum: 2},
+		{Rate: types.NewDecCoinFromDec("JUNO", types.MustNewDecFromStr("1")), BlockNum: 2},
+	})
+
+	testCases := []struct {
+		action   string
+		expected types.DecCoins
+	}{
+		{
+			action:   OutlierDrop,
+			expected: types.DecCoins{rates[0], rates[2]},
+		},
+		{
+			action: OutlierClamp,
+			expected: types.DecCoins{
+				rates[0],
+				types.NewDecCoinFromDec("JUNO", types.MustNewDecFromStr("12")),
+				rates[2],
+			},
+		},
+		{
+			action:   OutlierFlag,
+			expected: rates,
+		},
+	}
+
+	for _, tc := range testCases {
+		rts.Run(tc.action, func() {
+			config := OutlierConfig{K: types.NewDec(2), Action: tc.action}
+			filtered, decisions := filterOutliers(rates, medians, deviations, config)
+			rts.Require().Equal(tc.expected, filtered)
+
+			// rates without a median are not checked
+			rts.Require().Len(decisions, 2)
+			rts.Require().False(decisions[0].outlier)
+			rts.Require().True(decisions[1].outlier)
+		})
+	}
+}
+
+func (rts *RelayerTestSuite) Test_rateHistory() {
+	start := time.Unix(0, 0)
+	history := newRateHistory([]SeriesConfig{{Kind: SeriesTWAP, Window: 30 * time.Minute}})
+
+	// atom is 1 for 10 minutes, then 4 for 20 minutes
+	history.add(1, start, types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(1))})
+	history.add(2, start.Add(10*time.Minute), types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(4))})
+
+	// already sampled heights are ignored
+	history.add(2, start.Add(20*time.Minute), types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(100))})
+
+	_, warm := history.twap("ATOM", start.Add(20*time.Minute), 30*time.Minute)
+	rts.Require().False(warm)
+
+	twap, warm := history.twap("ATOM", start.Add(30*time.Minute), 30*time.Minute)
+	rts.Require().True(warm)
+	rts.Require().Equal(types.NewDec(3), twap)
+
+	ema, warm := history.ema("ATOM", start.Add(30*time.Minute), 10*time.Minute)
+	rts.Require().True(warm)
+	rts.Require().True(ema.GT(types.NewDec(1)) && ema.LT(types.NewDec(4)))
+
+	// samples older than the retention are dropped
+	history.add(3, start.Add(2*time.Hour), types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(2))})
+	rts.Require().Len(history.samples["ATOM"], 2)
+}
+
+func (rts *RelayerTestSuite) Test_warmUp() {
+	// a pruned lcd node serving the latest height only
+	cdc := codec.NewProtoCodec(codectypes.NewInterfaceRegistry())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp codec.ProtoMarshaler
+		switch {
+		case len(r.Header.Get(grpctypes.GRPCBlockHeightHeader)) > 0:
+			http.NotFound(w, r)
+			return
+		case r.URL.Path == "/ojo/oracle/v1/denoms/exchange_rates/":
+			resp = &oracletypes.QueryExchangeRatesResponse{}
+		case r.URL.Path == "/cosmos/base/tendermint/v1beta1/blocks/1000":
+			resp = &tmservice.GetBlockByHeightResponse{Block: &tmproto.Block{Header: tmproto.Header{Time: time.Now()}}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Grpc-Metadata-"+grpctypes.GRPCBlockHeightHeader, "1000")
+		bz, err := cdc.MarshalJSON(resp)
+		rts.Require().NoError(err)
+		w.Write(bz) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	endpoints := []endpoint.Endpoint{
+		{Address: srv.URL, Source: client.SourceLCD},
+		{Address: srv.URL + "/", Source: client.SourceLCD},
+	}
+	pool, err := client.NewEndpointPool(zerolog.Nop(), endpoints, nil, 1, 0)
+	rts.Require().NoError(err)
+	defer pool.Close()
+
+	relayer := &Relayer{
+		logger:  zerolog.Nop(),
+		pool:    pool,
+		history: newRateHistory([]SeriesConfig{{Kind: SeriesTWAP, Window: time.Hour}}),
+	}
+
+	// failed history queries neither penalise nor switch the endpoint
+	err = relayer.warmUp(context.Background())
+	rts.Require().True(client.IsDataError(err))
+	rts.Require().Zero(pool.Switches(time.Hour))
+}
+
+func (rts *RelayerTestSuite) Test_sampleRates() {
+	series := []SeriesConfig{{Kind: SeriesTWAP, Window: 30 * time.Minute, Suffix: "TWAP"}}
+	relayer := &Relayer{
+		logger:     zerolog.Nop(),
+		derived:    DerivedConfig{Series: series},
+		history:    newRateHistory(series),
+		blockTimes: newBlockTimeCache(),
+	}
+
+	// rates are sampled at the time of the ojo block they were queried at
+	start := time.Unix(1_000_000, 0)
+	for i, amount := range []int64{1, 4, 4, 4} {
+		height := int64(10 + i)
+		relayer.blockTimes.set(uint64(height), start.Add(time.Duration(i)*10*time.Minute).Unix())
+		relayer.queryHeight = height
+
+		sampleTime, sampled := relayer.sampleRates(context.Background(), types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(amount))})
+		rts.Require().True(sampled)
+		rts.Require().Equal(start.Add(time.Duration(i)*10*time.Minute), sampleTime)
+	}
+
+	derived := relayer.derivedRates(start.Add(30*time.Minute), types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(4))})
+	rts.Require().Equal(types.DecCoins{{Denom: "ATOM.TWAP", Amount: types.NewDec(3)}}, derived)
+
+	// rates without an ojo height are not sampled
+	relayer.queryHeight = 0
+	_, sampled := relayer.sampleRates(context.Background(), types.DecCoins{types.NewDecCoin("ATOM", types.NewInt(1))})
+	rts.Require().False(sampled)
+}
+
+func (rts *RelayerTestSuite) Test_syntheticSymbol() {
+	rates := types.DecCoins{
+		types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("10")),
+		types.NewDecCoinFromDec("OJO", types.MustNewDecFromStr("2")),
+	}
+
+	testCases := []struct {
+		expr      string
+		expected  string
+		parseErr  bool
+		evalError bool
+	}{
+		{expr: "(ATOM + OJO) / 2", expected: "6"},
+		{expr: "atom * 1.15", expected: "11.5"},
+		{expr: "1 / OJO", expected: "0.5"},
+		{expr: "ATOM - -OJO * 2", expected: "14"},
+		{expr: "ATOM / (OJO - 2)", evalError: true},
+		{expr: "ATOM * JUNO", evalError: true},
+		{expr: "ATOM/OJO", expected: "5"},
+		{expr: "ATOM*OJO", expected: "20"},
+		{expr: "(ATOM/OJO)*2", expected: "10"},
+		{expr: "ATOM.5", parseErr: true},
+		{expr: "OJO - ATOM", evalError: true},
+		{expr: "(ATOM + OJO", parseErr: true},
+		{expr: "ATOM OJO", parseErr: true},
+		{expr: "ATOM $ 2", parseErr: true},
+	}
+
+	for _, tc := range testCases {
+		rts.Run(tc.expr, func() {
+			symbol, err := NewSyntheticSymbol("SYN", tc.expr)
+			if tc.parseErr {
+				rts.Require().Error(err)
+				return
+			}
+			rts.Require().NoError(err)
+
+			rate, err := symbol.Eval(rates)
+			if tc.evalError {
+				rts.Require().Error(err)
+				return
+			}
+			rts.Require().NoError(err)
+			rts.Require().Equal("SYN", rate.Denom)
+			rts.Require().Equal(types.MustNewDecFromStr(tc.expected), rate.Amount)
+		})
+	}
+}
+
+func (rts *RelayerTestSuite) Test_validateSyntheticDenoms() {
+	acceptList := []string{"ATOM", "OJO"}
+
+	symbol, err := NewSyntheticSymbol("SYN", "(atom / OJO) * 2")
+	rts.Require().NoError(err)
+	rts.Require().Equal([]string{"ATOM", "OJO"}, symbol.Denoms())
+	rts.Require().NoError(validateSyntheticDenoms([]SyntheticSymbol{symbol}, acceptList))
+
+	// unknown denoms fail on startup
+	unknown, err := NewSyntheticSymbol("UNKNOWN", "ATOM / JUNO")
+	rts.Require().NoError(err)
+	rts.Require().ErrorContains(validateSyntheticDenoms([]SyntheticSymbol{symbol, unknown}, acceptList), "JUNO")
+}
+
+func (rts *RelayerTestSuite) Test_checkSyntheticDenoms() {
+	unknown, err := NewSyntheticSymbol("UNKNOWN", "ATOM / JUNO")
+	rts.Require().NoError(err)
+
+	pool, err := client.NewEndpointPool(zerolog.Nop(), []endpoint.Endpoint{endpoint.New("localhost:1")}, nil, 0, 0)
+	rts.Require().NoError(err)
+	defer pool.Close()
+
+	relayer := &Relayer{logger: zerolog.Nop(), pool: pool, synthetic: []SyntheticSymbol{unknown}}
+
+	// a failed accept list query defers the check to the next params refresh
+	rts.Require().NoError(relayer.checkSyntheticDenoms(context.Background()))
+	rts.Require().True(relayer.syntheticPending)
+	rts.Require().True(relayer.paramsDue(time.Now()))
+	rts.Require().NoError(relayer.checkPendingSyntheticDenoms())
+
+	relayer.params = oracleParams{acceptList: []string{"ATOM", "OJO"}, refreshed: time.Now()}
+	rts.Require().ErrorContains(relayer.checkPendingSyntheticDenoms(), "JUNO")
+
+	relayer.params.acceptList = append(relayer.params.acceptList, "JUNO")
+	rts.Require().NoError(relayer.checkPendingSyntheticDenoms())
+	rts.Require().False(relayer.syntheticPending)
+}
+
+func (rts *RelayerTestSuite) Test_checkRefData() {
+	rate := types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("1.5"))
+	data := []byte(`{"rate":"1500000000","resolve_time":"100","request_id":"7"}`)
+
+	rts.Require().NoError(checkRefData(data, rate, 7, 100))
+	rts.Require().Error(checkRefData(data, rate, 8, 100))
+	rts.Require().Error(checkRefData(data, rate, 7, 101))
+	rts.Require().Error(checkRefData(data, types.NewDecCoinFromDec("ATOM", types.OneDec()), 7, 100))
+}
+
+func (rts *RelayerTestSuite) Test_topUpAmount() {
+	config := TopUpConfig{
+		Floor:    types.NewInt(100),
+		Amount:   types.NewInt(50),
+		DailyCap: types.NewInt(120),
+	}
+	now := time.Now()
+
+	rts.Require().Equal(alert.SeverityCritical, balanceSeverity(types.NewInt(5), BalanceConfig{Warning: types.NewInt(100), Critical: types.NewInt(10)}))
+	rts.Require().Equal(alert.SeverityWarning, balanceSeverity(types.NewInt(50), BalanceConfig{Warning: types.NewInt(100), Critical: types.NewInt(10)}))
+	rts.Require().Empty(balanceSeverity(types.NewInt(100), BalanceConfig{Warning: types.NewInt(100)}))
+
+	rts.Require().True(topUpAmount(types.NewInt(100), config, nil).IsZero())
+	rts.Require().Equal(types.NewInt(50), topUpAmount(types.NewInt(99), config, nil))
+
+	topUps := []topUp{
+		{amount: types.NewInt(50), time: now.Add(-25 * time.Hour)},
+		{amount: types.NewInt(50), time: now.Add(-time.Hour)},
+		{amount: types.NewInt(50), time: now},
+	}
+	topUps = recentTopUps(topUps, now)
+	rts.Require().Len(topUps, 2)
+	rts.Require().Equal(types.NewInt(20), topUpAmount(types.NewInt(99), config, topUps))
+
+	topUps = append(topUps, topUp{amount: types.NewInt(20), time: now})
+	rts.Require().True(topUpAmount(types.NewInt(99), config, topUps).IsZero())
+}
+
+func (rts *RelayerTestSuite) Test_decodeEventRates() {
+	newEvent := func(denom, rate string) abcitypes.Event {
+		return abcitypes.Event{
+			Type: "ojo.oracle.v1.EventSetFxRate",
+			Attributes: []abcitypes.EventAttribute{
+				{Key: "denom", Value: denom},
+				{Key: "rate", Value: rate},
+			},
+		}
+	}
+
+	testCases := []struct {
+		tc        string
+		events    []abcitypes.Event
+		expected  types.DecCoins
+		expectErr bool
+	}{
+		{
+			tc: "typed events",
+			events: []abcitypes.Event{
+				newEvent(`"UMEE"`, `"1.23456789"`),
+				newEvent(`"ATOM"`, `"1.13456789"`),
+			},
+			expected: types.DecCoins{
+				types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("1.13456789")),
+				types.NewDecCoinFromDec("UMEE", types.MustNewDecFromStr("1.23456789")),
+			},
+		},
+		{
+			tc:       "events without rates",
+			events:   []abcitypes.Event{{Type: "ojo.oracle.v1.EventSetFxRate"}},
+			expected: types.DecCoins{},
+		},
+		{
+			tc:        "invalid rate",
+			events:    []abcitypes.Event{newEvent("ATOM", "rate")},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		rts.Run(tc.tc, func() {
+			rates, err := decodeEventRates(tc.events)
+			if tc.expectErr {
+				rts.Require().Error(err)
+				return
+			}
+
+			rts.Require().NoError(err)
+			rts.Require().Equal(tc.expected, rates)
+		})
+	}
+}
+
+func (rts *RelayerTestSuite) Test_ratesMatch() {
+	rates := types.DecCoins{
+		types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("10.00")),
+		types.NewDecCoinFromDec("UMEE", types.MustNewDecFromStr("1.00")),
+	}
+	close := types.DecCoins{
+		types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("10.01")),
+		types.NewDecCoinFromDec("UMEE", types.MustNewDecFromStr("1.00")),
+	}
+
+	rts.Require().True(ratesMatch(rates, rates, types.ZeroDec()))
+	rts.Require().False(ratesMatch(rates, close, types.ZeroDec()))
+	rts.Require().True(ratesMatch(rates, close, types.MustNewDecFromStr("0.001")))
+	rts.Require().False(ratesMatch(rates, close, types.MustNewDecFromStr("0.0001")))
+	rts.Require().False(ratesMatch(rates, rates[:1], types.MustNewDecFromStr("0.001")))
+}
+
+func (rts *RelayerTestSuite) Test_relayProfiles() {
+	rates := types.DecCoins{
+		types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr("10")),
+		types.NewDecCoinFromDec("EUR", types.MustNewDecFromStr("1.1")),
+		types.NewDecCoinFromDec("OJO", types.MustNewDecFromStr("2")),
+	}
+
+	relayer := &Relayer{profiles: map[string]RelayProfile{
+		"crypto": {Name: "crypto", Denoms: []string{"ATOM", "OJO"}},
+		"fx":     {Name: "fx", Denoms: []string{"eur", "ATOM"}},
+		"none":   {Name: "none", Denoms: []string{"UMEE"}},
+		"all":    {Name: "all"},
+	}}
+
+	testCases := []struct {
+		profiles []string
+		expected []string
+	}{
+		{profiles: []string{"crypto"}, expected: []string{"ATOM", "OJO"}},
+		{profiles: []string{"crypto", "fx"}, expected: []string{"ATOM", "EUR", "OJO"}},
+		{profiles: []string{"crypto", "all"}, expected: []string{"ATOM", "EUR", "OJO"}},
+		{profiles: []string{"none"}, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		rts.Run(fmt.Sprint(tc.profiles), func() {
+			var denoms []string
+			for _, rate := range relayer.mergeProfiles(tc.profiles).filter(rates) {
+				denoms = append(denoms, rate.Denom)
+			}
+
+			rts.Require().Equal(tc.expected, denoms)
+		})
+	}
+
+	// profile denoms ignore case
+	stamps := []PriceStamp{{Rate: rates[0]}, {Rate: rates[1]}}
+	rts.Require().Equal(stamps, relayer.profiles["fx"].filterStamps(stamps))
+	rts.Require().Equal([]string{"EUR"}, relayer.profiles["fx"].filterDenoms([]string{"EUR", "OJO"}))
+}
+
+// heightSource is a price source serving prices at fixed heights.
+type heightSource struct {
+	client.PriceSource
+	ratesHeight, mediansHeight int64
+}
+
+func (s heightSource) ExchangeRates(context.Context, int64) (types.DecCoins, int64, error) {
+	return types.DecCoins{types.NewDecCoinFromDec("ATOM", types.OneDec())}, s.ratesHeight, nil
+}
+
+func (s heightSource) Medians(context.Context, int64) ([]oracletypes.PriceStamp, int64, error) {
+	rate := types.NewDecCoinFromDec("ATOM", types.OneDec())
+	return []oracletypes.PriceStamp{{ExchangeRate: &rate, BlockNum: 1}}, s.mediansHeight, nil
+}
+
+func (rts *RelayerTestSuite) Test_queryHeight() {
+	relayer := &Relayer{queryTimeout: time.Second}
+	ctx := context.Background()
+
+	testCases := []struct {
+		tc        string
+		source    heightSource
+		height    int64
+		expectErr bool
+	}{
+		{tc: "pinned height", source: heightSource{ratesHeight: 10, mediansHeight: 10}, height: 10},
+		{tc: "latest height pins medians", source: heightSource{ratesHeight: 12, mediansHeight: 12}},
+		{tc: "rates at another height", source: heightSource{ratesHeight: 11, mediansHeight: 10}, height: 10, expectErr: true},
+		{tc: "medians at another height", source: heightSource{ratesHeight: 10, mediansHeight: 11}, height: 10, expectErr: true},
+		{tc: "medians at another latest height", source: heightSource{ratesHeight: 12, mediansHeight: 13}, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		rts.Run(tc.tc, func() {
+			prices, err := relayer.queryDenomPrices(ctx, tc.source, true, false, nil, tc.height)
+			if tc.expectErr {
+				rts.Require().Error(err)
+				return
+			}
+
+			rts.Require().NoError(err)
+			rts.Require().Equal(tc.source.ratesHeight, prices.height)
+			rts.Require().Len(prices.medians, 1)
+		})
+	}
+
+	// prices of the last relayed height are not relayed again
+	relayer.lastRelayedHeight = 10
+	for height, expectErr := range map[int64]bool{9: true, 10: true, 11: false} {
+		relayer.queryHeight = height
+		rts.Require().Equal(expectErr, relayer.checkRelayHeight() != nil, "height %d", height)
+	}
+}
+
+func (rts *RelayerTestSuite) Test_resolveQuorum() {
+	newPrices := func(amount string) denomPrices {
+		return denomPrices{
+			height:        10,
+			exchangeRates: types.DecCoins{types.NewDecCoinFromDec("ATOM", types.MustNewDecFromStr(amount))},
+		}
+	}
+
+	// first ranked endpoint of the pool, to check for demotions
+	first := func(pool *client.EndpointPool) string {
+		var first string
+		rts.Require().NoError(pool.Do(context.Background(), func(address string, _ client.PriceSource) error {
+			first = address
+			return nil
+		}))
+
+		return first
+	}
+
+	testCases := []struct {
+		tc      string
+		results []string
+		applied bool
+		demoted bool
+	}{
+		{tc: "all match", results: []string{"1", "1", "1", "1"}, applied: true},
+		{tc: "strict majority", results: []string{"2", "1", "1", "1"}, demoted: true},
+		{tc: "tie", results: []string{"2", "2", "1", "1"}},
+		{tc: "no majority", results: []string{"3", "2", "1", "1"}},
+	}
+
+	for _, tc := range testCases {
+		rts.Run(tc.tc, func() {
+			var endpoints []endpoint.Endpoint
+			var addresses []string
+			results := map[string]denomPrices{}
+			for i, amount := range tc.results {
+				address := fmt.Sprintf("localhost:%d", i+1)
+				endpoints = append(endpoints, endpoint.New(address))
+				addresses = append(addresses, address)
+				results[address] = newPrices(amount)
+			}
+
+			pool, err := client.NewEndpointPool(zerolog.Nop(), endpoints, nil, 0, 0)
+			rts.Require().NoError(err)
+			defer pool.Close()
+
+			relayer := &Relayer{logger: zerolog.Nop(), pool: pool}
+			err = relayer.resolveQuorum(10, addresses, results)
+			rts.Require().Equal(tc.applied, err == nil)
+			rts.Require().Equal(tc.applied, !relayer.exchangeRates.Empty())
+
+			// the first ranked endpoint is only demoted if it disagrees with a strict majority
+			if tc.demoted {
+				rts.Require().Equal("localhost:2", first(pool))
+			} else {
+				rts.Require().Equal("localhost:1", first(pool))
+			}
+		})
+	}
+}
+
+func (rts *RelayerTestSuite) Test_checkMedianError() {
+	filter := OutlierConfig{K: types.NewDec(3), Action: OutlierDrop}
+
+	testCases := []struct {
+		tc             string
+		ignore         bool
+		outliers       OutlierConfig
+		postHistorical bool
+		expectErr      bool
+	}{
+		{tc: "posting tick", postHistorical: true, expectErr: true},
+		{tc: "non posting tick", postHistorical: false},
+		{tc: "outlier filter on a non posting tick", outliers: filter, expectErr: true},
+		{tc: "ignored with outlier filter", ignore: true, outliers: filter, postHistorical: true},
+		{tc: "ignored on a posting tick", ignore: true, postHistorical: true},
+	}
+
+	for _, tc := range testCases {
+		rts.Run(tc.tc, func() {
+			relayer := &Relayer{logger: zerolog.Nop(), ignoreMedianErrors: tc.ignore, outliers: tc.outliers}
+			err := relayer.checkMedianError(noMedians, tc.postHistorical, 10)
+			if tc.expectErr {
+				rts.Require().ErrorIs(err, noMedians)
+				return
+			}
+
+			rts.Require().NoError(err)
+		})
+	}
+}
+
+func (rts *RelayerTestSuite) Test_startVerify() {
+	relayer := &Relayer{logger: zerolog.Nop(), verifying: make(chan struct{}, maxVerifications)}
+	for i := 0; i < maxVerifications; i++ {
+		relayer.verifying <- struct{}{}
+	}
+
+	// verifications beyond the bound are skipped without blocking the tick
+	relayer.startVerify(context.Background(), "hash", 1, 0, types.NewDecCoins(types.NewDecCoin("ATOM", types.NewInt(1))))
+	rts.Require().Len(relayer.verifying, maxVerifications)
+}
+
+func (rts *RelayerTestSuite) Test_checkCompletenessResolve() {
+	sink := alert.NewLogSink(zerolog.Nop())
+	relayer := &Relayer{
+		logger:       zerolog.Nop(),
+		completeness: CompletenessConfig{ExpectedDenoms: []string{"ATOM", "OJO"}, Policy: CompletenessAlert},
+		alerts:       AlertConfig{Alerter: alert.NewAlerter(zerolog.Nop(), []alert.Sink{sink}, time.Hour, time.Hour)},
+	}
+
+	partial := types.NewDecCoins(types.NewDecCoin("ATOM", types.NewInt(1)))
+	complete := partial.Add(types.NewDecCoin("OJO", types.NewInt(1)))
+
+	rts.Require().NoError(relayer.checkCompleteness(RelayProfile{}, partial))
+	rts.Require().NoError(relayer.checkCompleteness(RelayProfile{}, partial))
+
+	// a complete relay resolves the incident, so the next missing denom is alerted again
+	rts.Require().NoError(relayer.checkCompleteness(RelayProfile{}, complete))
+	rts.Require().NoError(relayer.checkCompleteness(RelayProfile{}, partial))
+
+	rts.Require().Eventually(func() bool { return len(sink.Alerts()) == 2 }, time.Second, 10*time.Millisecond)
+}
+
+func (rts *RelayerTestSuite) Test_lowSpendLimit() {
+	warning := types.NewCoins(types.NewInt64Coin("stake", 100), types.NewInt64Coin("uojo", 10))
+
+	rts.Require().Empty(lowSpendLimit(nil, warning))
+	rts.Require().Empty(lowSpendLimit(types.NewCoins(types.NewInt64Coin("stake", 100), types.NewInt64Coin("uojo", 10)), warning))
+	rts.Require().Equal(
+		types.NewCoins(types.NewInt64Coin("stake", 100)),
+		lowSpendLimit(types.NewCoins(types.NewInt64Coin("stake", 99), types.NewInt64Coin("uojo", 10)), warning),
+	)
+
+	// a limited allowance without the denom cannot pay fees in it
+	rts.Require().Equal(
+		types.NewCoins(types.NewInt64Coin("uojo", 10)),
+		lowSpendLimit(types.NewCoins(types.NewInt64Coin("stake", 100)), warning),
+	)
+}
+
+func (rts *RelayerTestSuite) Test_requestFeeGrantCheck() {
+	relayer := &Relayer{feeGrantCheck: make(chan struct{}, 1)}
+
+	relayer.requestFeeGrantCheck(errors.New("broadcasting tx timed out"))
+	rts.Require().Len(relayer.feeGrantCheck, 0)
+
+	// repeated rejections do not block while a check is pending
+	relayer.requestFeeGrantCheck(fmt.Errorf("%w: fee limit exceeded", client.ErrFeeGrant))
+	relayer.requestFeeGrantCheck(fmt.Errorf("%w: fee limit exceeded", client.ErrFeeGrant))
+	rts.Require().Len(relayer.feeGrantCheck, 1)
+}
@@ -0,0 +1,92 @@
+package relayer
+
+import (
+	"time"
+)
+
+const (
+	// ClockBlock measures schedule intervals on the wasmd block time.
+	ClockBlock = "block"
+	// ClockWall measures schedule intervals on the relayer wall clock.
+	ClockWall = "wall"
+)
+
+// ScheduleConfig defines the time intervals between median and deviation relays, measured since
+// the last successful relay on the clock. The request id based median and deviation durations
+// are used if an interval is zero. The wasmd block time is used if the clock is not set.
+// If FromParams is set, medians and deviations are relayed once per ojo median stamp period
+// instead, and the ojo oracle params are queried again every ParamsRefresh.
+type ScheduleConfig struct {
+	Clock             string
+	MedianInterval    time.Duration
+	DeviationInterval time.Duration
+	FromParams        bool
+	ParamsRefresh     time.Duration
+}
+
+// now returns the current time on the schedule clock.
+func (s ScheduleConfig) now(blockTimestamp time.Time) time.Time {
+	if s.Clock == ClockWall {
+		return time.Now()
+	}
+
+	return blockTimestamp
+}
+
+// historicalDue returns whether the median and deviation relays are due at the schedule time and ojo height.
+func (r *Relayer) historicalDue(scheduleTime time.Time, height int64) (postMedian, postDeviation bool) {
+	if r.schedule.FromParams && r.params.medianStampPeriod > 0 {
+		// the event height is not known when polling, use the last queried height
+		if height <= 0 {
+			height = r.queryHeight
+		}
+
+		period := int64(r.params.medianStampPeriod)
+		return dueHeight(period, r.lastMedianHeight, height), dueHeight(period, r.lastDeviationHeight, height)
+	}
+
+	postMedian = due(r.schedule.MedianInterval, r.medianDuration, r.lastMedianRelay, scheduleTime, r.requestID)
+	postDeviation = due(r.schedule.DeviationInterval, r.deviationDuration, r.lastDeviationRelay, scheduleTime, r.requestID)
+	return postMedian, postDeviation
+}
+
+// historicalResolveTimes returns the resolve times of the median and deviation relays posted at the block timestamp.
+func (r *Relayer) historicalResolveTimes(blockTimestamp time.Time) (median, deviation int64) {
+	if r.schedule.FromParams && r.params.medianStampPeriod > 0 {
+		ticks := r.params.ticks(r.skipNumEvents)
+		return r.historicalResolveTime(blockTimestamp, 0, ticks), r.historicalResolveTime(blockTimestamp, 0, ticks)
+	}
+
+	return r.historicalResolveTime(blockTimestamp, r.schedule.MedianInterval, r.medianDuration),
+		r.historicalResolveTime(blockTimestamp, r.schedule.DeviationInterval, r.deviationDuration)
+}
+
+// dueHeight returns true if period ojo blocks passed since the height of the last successful relay.
+func dueHeight(period, last, height int64) bool {
+	return last == 0 || height-last >= period
+}
+
+// due returns true if a historical relay is due. With an interval, the relay is due once the
+// interval has passed since the last successful relay, so a failed relay is retried on the next tick.
+// Otherwise the relay is due every duration request ids.
+func due(interval time.Duration, duration int64, last, now time.Time, requestID uint64) bool {
+	if interval > 0 {
+		return last.IsZero() || !now.Before(last.Add(interval))
+	}
+
+	if duration > 0 {
+		return requestID%uint64(duration) == 0
+	}
+
+	return false
+}
+
+// historicalResolveTime returns the resolve time of a historical relay posted at the block timestamp.
+func (r *Relayer) historicalResolveTime(blockTimestamp time.Time, interval time.Duration, duration int64) int64 {
+	if interval > 0 {
+		return blockTimestamp.Add(interval).Unix()
+	}
+
+	resolveTime := time.Duration(r.resolveDuration.Nanoseconds() * duration)
+	return blockTimestamp.Add(resolveTime).Unix()
+}
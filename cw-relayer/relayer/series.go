@@ -0,0 +1,291 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/relayer/client"
+)
+
+const (
+	// SeriesTWAP computes the time weighted average rate over the window.
+	SeriesTWAP = "twap"
+	// SeriesEMA computes the exponential moving average rate with the window as time constant.
+	SeriesEMA = "ema"
+
+	// samples are kept for emaRetention windows, so that the ema seed weighs less than 5%
+	emaRetention = 3
+	// number of ojo blocks used to estimate the ojo block time when warming up
+	warmupEstimateBlocks = 100
+	// number of samples queried from ojo history when warming up
+	warmupSamples = 60
+	// max duration of the warm up, the samples queried until then are kept
+	warmupTimeout = 30 * time.Second
+)
+
+type (
+	// DerivedConfig defines the smoothed series relayed as additional symbols. Rates are sampled
+	// at the time of the ojo block they were queried at. If Warmup is set, the rate history is
+	// backfilled from ojo on startup, otherwise series are relayed once the relayer has sampled
+	// rates for a whole window.
+	DerivedConfig struct {
+		Series []SeriesConfig
+		Warmup bool
+	}
+
+	// SeriesConfig defines a TWAP or EMA series of the denoms, relayed as DENOM.Suffix.
+	// All relayed denoms are used if Denoms is empty.
+	SeriesConfig struct {
+		Denoms []string
+		Kind   string
+		Window time.Duration
+		Suffix string
+	}
+
+	// rateHistory keeps a rolling window of the sampled rates of each denom.
+	rateHistory struct {
+		retention time.Duration
+		height    int64
+		samples   map[string][]rateSample
+	}
+
+	rateSample struct {
+		time time.Time
+		rate types.Dec
+	}
+)
+
+// newRateHistory returns a history keeping samples for the longest window of the series.
+func newRateHistory(series []SeriesConfig) *rateHistory {
+	var retention time.Duration
+	for _, s := range series {
+		window := s.Window
+		if s.Kind == SeriesEMA {
+			window *= emaRetention
+		}
+
+		if window > retention {
+			retention = window
+		}
+	}
+
+	return &rateHistory{retention: retention, samples: map[string][]rateSample{}}
+}
+
+// add samples the rates queried at the ojo height, rates of an already sampled height are ignored.
+func (h *rateHistory) add(height int64, t time.Time, rates types.DecCoins) {
+	if height > 0 && height <= h.height {
+		return
+	}
+
+	h.height = height
+	for _, rate := range rates {
+		samples := append(h.samples[rate.Denom], rateSample{time: t, rate: rate.Amount})
+
+		// keep the newest sample older than the retention, it covers the start of the window
+		start := 0
+		for start+1 < len(samples) && !samples[start+1].time.After(t.Add(-h.retention)) {
+			start++
+		}
+
+		h.samples[rate.Denom] = samples[start:]
+	}
+}
+
+// twap returns the time weighted average rate of the denom over the window ending at now,
+// each sampled rate holds until the next sample. It returns false if the window is not covered.
+func (h *rateHistory) twap(denom string, now time.Time, window time.Duration) (types.Dec, bool) {
+	samples := h.samples[denom]
+	start := now.Add(-window)
+	if len(samples) == 0 || samples[0].time.After(start) || window <= 0 {
+		return types.Dec{}, false
+	}
+
+	sum := types.ZeroDec()
+	for i, sample := range samples {
+		from := sample.time
+		if from.Before(start) {
+			from = start
+		}
+
+		to := now
+		if i+1 < len(samples) {
+			to = samples[i+1].time
+		}
+
+		if !to.After(from) {
+			continue
+		}
+
+		sum = sum.Add(sample.rate.MulInt64(int64(to.Sub(from))))
+	}
+
+	return sum.QuoInt64(int64(window)), true
+}
+
+// ema returns the exponential moving average rate of the denom, weighting samples by the time
+// elapsed between them. It returns false if the samples do not cover the window.
+func (h *rateHistory) ema(denom string, now time.Time, window time.Duration) (types.Dec, bool) {
+	samples := h.samples[denom]
+	if len(samples) == 0 || samples[0].time.After(now.Add(-window)) || window <= 0 {
+		return types.Dec{}, false
+	}
+
+	average := samples[0].rate
+	for i := 1; i < len(samples); i++ {
+		elapsed := samples[i].time.Sub(samples[i-1].time)
+		alpha, err := types.NewDecFromStr(
+			strconv.FormatFloat(1-math.Exp(-float64(elapsed)/float64(window)), 'f', types.Precision, 64),
+		)
+		if err != nil {
+			return types.Dec{}, false
+		}
+
+		average = average.Add(samples[i].rate.Sub(average).Mul(alpha))
+	}
+
+	return average, true
+}
+
+// symbol returns the derived symbol of the denom.
+func (s SeriesConfig) symbol(denom string) string {
+	return fmt.Sprintf("%s.%s", denom, s.Suffix)
+}
+
+// derivedRates returns the warm series of the relayed rates, denoms without a covered window are skipped.
+func (r *Relayer) derivedRates(now time.Time, rates types.DecCoins) types.DecCoins {
+	var derived types.DecCoins
+	for _, series := range r.derived.Series {
+		for _, rate := range rates {
+			if len(series.Denoms) > 0 && !containsDenom(series.Denoms, rate.Denom) {
+				continue
+			}
+
+			var (
+				value types.Dec
+				warm  bool
+			)
+			switch series.Kind {
+			case SeriesEMA:
+				value, warm = r.history.ema(rate.Denom, now, series.Window)
+			default:
+				value, warm = r.history.twap(rate.Denom, now, series.Window)
+			}
+
+			if !warm {
+				r.logger.Debug().Str("symbol", series.symbol(rate.Denom)).Msg("derived series warming up")
+				continue
+			}
+
+			derived = append(derived, types.DecCoin{Denom: series.symbol(rate.Denom), Amount: value})
+		}
+	}
+
+	return derived
+}
+
+// sampleRates adds the rates to the rate history at the time of the queried ojo block,
+// and returns the block time. It returns false if the block time is unknown.
+func (r *Relayer) sampleRates(ctx context.Context, rates types.DecCoins) (time.Time, bool) {
+	if len(r.derived.Series) == 0 || r.queryHeight <= 0 {
+		return time.Time{}, false
+	}
+
+	blockTime, err := r.ojoBlockTime(ctx, r.queryHeight)
+	if err != nil {
+		r.logger.Err(err).Int64("ojo height", r.queryHeight).Msg("error querying ojo block time, skipping derived series")
+		return time.Time{}, false
+	}
+
+	r.history.add(r.queryHeight, blockTime, rates)
+	return blockTime, true
+}
+
+// warmUp backfills the rate history with exchange rates queried from ojo history,
+// spread evenly over the retention of the longest series. The warm up is bounded
+// by warmupTimeout, so that it does not delay the first relay for long. History
+// queries fail on pruned nodes, so their errors are returned as data errors that
+// neither penalise nor switch the endpoint.
+func (r *Relayer) warmUp(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+	defer cancel()
+
+	return r.pool.Do(ctx, func(_ string, source client.PriceSource) error {
+		_, height, err := source.ExchangeRates(ctx, 0)
+		if err != nil {
+			return err
+		}
+
+		if height <= warmupEstimateBlocks {
+			return client.NewDataError(fmt.Sprintf("ojo height %d too low to warm up", height))
+		}
+
+		latest, err := source.BlockTime(ctx, height)
+		if err != nil {
+			return err
+		}
+
+		past, err := source.BlockTime(ctx, height-warmupEstimateBlocks)
+		if err != nil {
+			return historyError(height-warmupEstimateBlocks, err)
+		}
+
+		blockTime := latest.Sub(past) / warmupEstimateBlocks
+		if blockTime <= 0 {
+			return client.NewDataError(fmt.Sprintf("invalid ojo block time %s", blockTime))
+		}
+
+		blocks := int64(r.history.retention / blockTime)
+		step := blocks / warmupSamples
+		if step < 1 {
+			step = 1
+		}
+
+		for sampleHeight := height - blocks; sampleHeight < height; sampleHeight += step {
+			if sampleHeight < 1 {
+				continue
+			}
+
+			rates, _, err := source.ExchangeRates(ctx, sampleHeight)
+			if err != nil {
+				return historyError(sampleHeight, err)
+			}
+
+			sampleTime, err := source.BlockTime(ctx, sampleHeight)
+			if err != nil {
+				return historyError(sampleHeight, err)
+			}
+
+			r.history.add(sampleHeight, sampleTime, rates)
+		}
+
+		r.logger.Info().
+			Int64("from height", height-blocks).
+			Int64("to height", height).
+			Msg("derived series warmed up from ojo history")
+
+		return nil
+	})
+}
+
+// historyError returns a failed history query at the given height as a data error.
+func historyError(height int64, err error) error {
+	return client.NewDataError(fmt.Sprintf("ojo history at height %d: %s", height, err))
+}
+
+// containsDenom returns true if the denoms contain the denom, ignoring case.
+func containsDenom(denoms []string, denom string) bool {
+	for _, d := range denoms {
+		if strings.EqualFold(d, denom) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+)
+
+// StalenessConfig defines when ojo prices are considered stale. Prices are stale if the
+// exchange rates have not changed for MaxUnchangedTicks ticks at new ojo heights, or if the ojo
+// block they were queried at is older than MaxAge. Each check is disabled if it is zero.
+type StalenessConfig struct {
+	MaxUnchangedTicks int64
+	MaxAge            time.Duration
+}
+
+// staleness tracks the exchange rates of the last ticks and the block time of the queried ojo height.
+type staleness struct {
+	rates          types.DecCoins
+	height         int64
+	blockTime      time.Time
+	unchangedTicks int64
+	stale          bool
+}
+
+// observe records the exchange rates of a tick at the ojo height and returns the number of
+// consecutive ticks the rates have not changed. Rates are observed once per ojo height,
+// so that several ticks at the same height do not count as unchanged rates.
+func (s *staleness) observe(height int64, rates types.DecCoins) int64 {
+	if height > 0 && height == s.height {
+		return s.unchangedTicks
+	}
+
+	s.height = height
+	if ratesMatch(rates, s.rates, types.ZeroDec()) {
+		s.unchangedTicks++
+	} else {
+		s.unchangedTicks = 0
+	}
+
+	s.rates = rates
+	return s.unchangedTicks
+}
+
+// checkStaleness returns an error and raises an alert if the queried ojo prices are stale,
+// so that the resolve time of the contract is not advanced on frozen prices.
+func (r *Relayer) checkStaleness(ctx context.Context) error {
+	if r.staleness.MaxUnchangedTicks <= 0 && r.staleness.MaxAge <= 0 {
+		return nil
+	}
+
+	unchangedTicks := r.stale.observe(r.queryHeight, r.exchangeRates)
+
+	var reason string
+	if r.staleness.MaxUnchangedTicks > 0 && unchangedTicks >= r.staleness.MaxUnchangedTicks {
+		reason = fmt.Sprintf("rates unchanged for %d ticks", unchangedTicks)
+	}
+
+	if r.staleness.MaxAge > 0 && r.queryHeight > 0 {
+		blockTime, err := r.ojoBlockTime(ctx, r.queryHeight)
+		if err != nil {
+			r.logger.Err(err).Int64("ojo height", r.queryHeight).Msg("error querying ojo block time")
+		} else {
+			r.stale.blockTime = blockTime
+			if age := time.Since(blockTime); age > r.staleness.MaxAge {
+				reason = fmt.Sprintf("ojo block is %s old", age.Truncate(time.Second))
+			}
+		}
+	}
+
+	if len(reason) == 0 {
+		if r.stale.stale {
+			r.logger.Info().Int64("ojo height", r.queryHeight).Msg("ojo prices recovered")
+			r.resolveAlert(AlertStalePrices)
+		}
+
+		r.stale.stale = false
+		return nil
+	}
+
+	r.stale.stale = true
+	r.alert(AlertStalePrices, alert.SeverityCritical, "ojo prices are stale", map[string]string{
+		"reason":     reason,
+		"ojo height": strconv.FormatInt(r.queryHeight, 10),
+	})
+	r.logger.Error().
+		Str("reason", reason).
+		Int64("ojo height", r.queryHeight).
+		Time("ojo block time", r.stale.blockTime).
+		Msg("ojo prices are stale")
+
+	return fmt.Errorf("ojo prices are stale: %s", reason)
+}
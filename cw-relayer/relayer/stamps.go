@@ -0,0 +1,206 @@
+package relayer
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types"
+	oracletypes "github.com/ojo-network/ojo/x/oracle/types"
+
+	"github.com/ojo-network/cw-relayer/relayer/client"
+)
+
+// StampFormat defines how historical median and deviation values are relayed to the contract.
+type StampFormat string
+
+const (
+	// StampFormatRates relays the rates of the historical stamps only.
+	StampFormatRates StampFormat = "rates"
+	// StampFormatStamps relays each historical rate with its ojo block number and block time.
+	StampFormatStamps StampFormat = "stamps"
+
+	// max number of ojo block times kept in the cache
+	maxBlockTimes = 1024
+)
+
+// PriceStamp is a historical ojo price with the ojo block number it was stamped at.
+// Timestamp is the unix time of the block, and is only set for the stamps format.
+type PriceStamp struct {
+	Rate      types.DecCoin
+	BlockNum  uint64
+	Timestamp int64
+}
+
+// HistoryWindow defines the number of most recent historical stamps relayed for each denom,
+// Denoms overrides Size for the given denoms. All stamps are relayed if the size is not positive.
+type HistoryWindow struct {
+	Size   int
+	Denoms map[string]int
+}
+
+// blockTimeCache caches the unix time of ojo blocks by block number.
+type blockTimeCache struct {
+	mtx   sync.Mutex
+	times map[uint64]int64
+}
+
+func newBlockTimeCache() *blockTimeCache {
+	return &blockTimeCache{times: map[uint64]int64{}}
+}
+
+func (c *blockTimeCache) get(blockNum uint64) (int64, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	timestamp, found := c.times[blockNum]
+	return timestamp, found
+}
+
+func (c *blockTimeCache) set(blockNum uint64, timestamp int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// stamps older than the ojo history window are not queried again
+	if len(c.times) >= maxBlockTimes {
+		c.times = map[uint64]int64{}
+	}
+
+	c.times[blockNum] = timestamp
+}
+
+// newPriceStamps converts ojo price stamps, sorted by denom and block number.
+func newPriceStamps(priceStamps []oracletypes.PriceStamp) []PriceStamp {
+	stamps := make([]PriceStamp, 0, len(priceStamps))
+	for _, priceStamp := range priceStamps {
+		if priceStamp.ExchangeRate == nil {
+			continue
+		}
+
+		stamps = append(stamps, PriceStamp{Rate: *priceStamp.ExchangeRate, BlockNum: priceStamp.BlockNum})
+	}
+
+	return sortStamps(stamps)
+}
+
+// sortStamps returns a copy of the stamps sorted by denom and block number,
+// stamps with the same denom and block number keep their order.
+func sortStamps(stamps []PriceStamp) []PriceStamp {
+	sorted := make([]PriceStamp, len(stamps))
+	copy(sorted, stamps)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Rate.Denom != sorted[j].Rate.Denom {
+			return sorted[i].Rate.Denom < sorted[j].Rate.Denom
+		}
+
+		return sorted[i].BlockNum < sorted[j].BlockNum
+	})
+
+	return sorted
+}
+
+// size returns the window size of the denom.
+func (w HistoryWindow) size(denom string) int {
+	if size, found := w.Denoms[denom]; found {
+		return size
+	}
+
+	return w.Size
+}
+
+// apply returns the most recent stamps of each denom within the window,
+// sorted by denom and block number.
+func (w HistoryWindow) apply(stamps []PriceStamp) []PriceStamp {
+	sorted := sortStamps(stamps)
+
+	counts := map[string]int{}
+	for _, stamp := range sorted {
+		counts[stamp.Rate.Denom]++
+	}
+
+	windowed := make([]PriceStamp, 0, len(sorted))
+	for _, stamp := range sorted {
+		denom := stamp.Rate.Denom
+		size := w.size(denom)
+
+		// skip the oldest stamps outside the window
+		if size > 0 && counts[denom] > size {
+			counts[denom]--
+			continue
+		}
+
+		windowed = append(windowed, stamp)
+	}
+
+	return windowed
+}
+
+// setStampTimes sets the block time of each stamp, querying the times missing from the cache.
+func (r *Relayer) setStampTimes(ctx context.Context, source client.PriceSource, stamps []PriceStamp) error {
+	for i, stamp := range stamps {
+		timestamp, found := r.blockTimes.get(stamp.BlockNum)
+		if !found {
+			blockTime, err := source.BlockTime(ctx, int64(stamp.BlockNum))
+			if err != nil {
+				return err
+			}
+
+			timestamp = blockTime.Unix()
+			r.blockTimes.set(stamp.BlockNum, timestamp)
+		}
+
+		stamps[i].Timestamp = timestamp
+	}
+
+	return nil
+}
+
+// ojoBlockTime returns the time of the ojo block at the height, querying it if missing from the cache.
+func (r *Relayer) ojoBlockTime(ctx context.Context, height int64) (time.Time, error) {
+	if timestamp, found := r.blockTimes.get(uint64(height)); found {
+		return time.Unix(timestamp, 0), nil
+	}
+
+	var blockTime time.Time
+	err := r.pool.Do(ctx, func(_ string, source client.PriceSource) error {
+		queryCtx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+		defer cancel()
+
+		var err error
+		blockTime, err = source.BlockTime(queryCtx, height)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	r.blockTimes.set(uint64(height), blockTime.Unix())
+	return blockTime, nil
+}
+
+// stampsMatch returns true if both lists have the same stamps,
+// with rates within the relative tolerance of each other.
+func stampsMatch(a, b []PriceStamp, tolerance types.Dec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].BlockNum != b[i].BlockNum {
+			return false
+		}
+	}
+
+	return ratesMatch(stampRates(a), stampRates(b), tolerance)
+}
+
+// stampRates returns the rates of the stamps.
+func stampRates(stamps []PriceStamp) types.DecCoins {
+	rates := make(types.DecCoins, len(stamps))
+	for i, stamp := range stamps {
+		rates[i] = stamp.Rate
+	}
+
+	return rates
+}
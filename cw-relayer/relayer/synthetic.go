@@ -0,0 +1,339 @@
+package relayer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/types"
+)
+
+type (
+	// SyntheticSymbol is a symbol relayed with the rate of an expression over the queried rates.
+	// Expressions support decimal numbers, denoms, + - * / and parentheses, e.g. "(ATOM + OJO) / 2".
+	// Denoms are made of letters, digits and underscores, so "ATOM/OJO" divides two denoms.
+	SyntheticSymbol struct {
+		Symbol string
+		expr   expression
+	}
+
+	// expression is a node of a parsed synthetic expression.
+	expression interface {
+		eval(rates map[string]types.Dec) (types.Dec, error)
+		denoms() []string
+	}
+
+	number struct {
+		value types.Dec
+	}
+
+	denomRef struct {
+		denom string
+	}
+
+	negation struct {
+		x expression
+	}
+
+	binary struct {
+		op   rune
+		x, y expression
+	}
+
+	// parser is a recursive descent parser of synthetic expressions.
+	parser struct {
+		input []rune
+		pos   int
+	}
+)
+
+// NewSyntheticSymbol parses the expression of the symbol.
+func NewSyntheticSymbol(symbol, expr string) (SyntheticSymbol, error) {
+	p := &parser{input: []rune(expr)}
+	parsed, err := p.parseExpr()
+	if err != nil {
+		return SyntheticSymbol{}, fmt.Errorf("invalid expression of %s: %w", symbol, err)
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return SyntheticSymbol{}, fmt.Errorf("invalid expression of %s: unexpected %q", symbol, p.input[p.pos])
+	}
+
+	return SyntheticSymbol{Symbol: symbol, expr: parsed}, nil
+}
+
+// Eval returns the rate of the symbol, or an error if an input rate is missing or the rate is not positive.
+func (s SyntheticSymbol) Eval(rates types.DecCoins) (types.DecCoin, error) {
+	rateMap := make(map[string]types.Dec, len(rates))
+	for _, rate := range rates {
+		rateMap[strings.ToUpper(rate.Denom)] = rate.Amount
+	}
+
+	value, err := s.expr.eval(rateMap)
+	if err != nil {
+		return types.DecCoin{}, err
+	}
+
+	if !value.IsPositive() {
+		return types.DecCoin{}, fmt.Errorf("rate %s is not positive", value)
+	}
+
+	return types.DecCoin{Denom: s.Symbol, Amount: value}, nil
+}
+
+// Denoms returns the uppercased denoms the expression of the symbol depends on.
+func (s SyntheticSymbol) Denoms() []string {
+	return s.expr.denoms()
+}
+
+// checkSyntheticDenoms returns an error if a synthetic symbol depends on a denom missing from
+// the ojo accept list, so that invalid expressions fail on startup instead of every tick.
+// If the accept list cannot be queried, the denoms are checked on the first successful params
+// refresh instead.
+func (r *Relayer) checkSyntheticDenoms(ctx context.Context) error {
+	if len(r.synthetic) == 0 {
+		return nil
+	}
+
+	if err := r.refreshParams(ctx); err != nil {
+		r.logger.Warn().Err(err).Msg("failed to query the ojo accept list, checking synthetic denoms on the next params refresh")
+		r.syntheticPending = true
+		return nil
+	}
+
+	return validateSyntheticDenoms(r.synthetic, r.params.acceptList)
+}
+
+// checkPendingSyntheticDenoms validates the synthetic denoms against the first refreshed accept list
+// if the startup check was deferred, relays fail until the expressions are fixed.
+func (r *Relayer) checkPendingSyntheticDenoms() error {
+	if !r.syntheticPending || r.params.refreshed.IsZero() {
+		return nil
+	}
+
+	if err := validateSyntheticDenoms(r.synthetic, r.params.acceptList); err != nil {
+		return err
+	}
+
+	r.syntheticPending = false
+	return nil
+}
+
+// validateSyntheticDenoms returns an error if a symbol depends on a denom missing from the accept list.
+func validateSyntheticDenoms(symbols []SyntheticSymbol, acceptList []string) error {
+	for _, symbol := range symbols {
+		for _, denom := range symbol.Denoms() {
+			if !containsDenom(acceptList, denom) {
+				return fmt.Errorf("unknown denom %s in the expression of %s", denom, symbol.Symbol)
+			}
+		}
+	}
+
+	return nil
+}
+
+// syntheticRates evaluates the synthetic symbols over the rates,
+// symbols with missing or invalid inputs are reported and skipped.
+func (r *Relayer) syntheticRates(rates types.DecCoins) types.DecCoins {
+	var synthetic types.DecCoins
+	for _, symbol := range r.synthetic {
+		rate, err := symbol.Eval(rates)
+		if err != nil {
+			telemetry.IncrCounter(1, "failure", "synthetic")
+			r.logger.Warn().Err(err).Str("symbol", symbol.Symbol).Msg("skipping synthetic symbol")
+			continue
+		}
+
+		synthetic = append(synthetic, rate)
+	}
+
+	return synthetic
+}
+
+func (n number) eval(map[string]types.Dec) (types.Dec, error) {
+	return n.value, nil
+}
+
+func (number) denoms() []string {
+	return nil
+}
+
+func (d denomRef) denoms() []string {
+	return []string{strings.ToUpper(d.denom)}
+}
+
+func (n negation) denoms() []string {
+	return n.x.denoms()
+}
+
+func (b binary) denoms() []string {
+	return append(b.x.denoms(), b.y.denoms()...)
+}
+
+func (d denomRef) eval(rates map[string]types.Dec) (types.Dec, error) {
+	rate, found := rates[strings.ToUpper(d.denom)]
+	if !found {
+		return types.Dec{}, fmt.Errorf("missing rate of %s", d.denom)
+	}
+
+	return rate, nil
+}
+
+func (n negation) eval(rates map[string]types.Dec) (types.Dec, error) {
+	x, err := n.x.eval(rates)
+	if err != nil {
+		return types.Dec{}, err
+	}
+
+	return x.Neg(), nil
+}
+
+func (b binary) eval(rates map[string]types.Dec) (types.Dec, error) {
+	x, err := b.x.eval(rates)
+	if err != nil {
+		return types.Dec{}, err
+	}
+
+	y, err := b.y.eval(rates)
+	if err != nil {
+		return types.Dec{}, err
+	}
+
+	switch b.op {
+	case '+':
+		return x.Add(y), nil
+	case '-':
+		return x.Sub(y), nil
+	case '*':
+		return x.Mul(y), nil
+	default:
+		if y.IsZero() {
+			return types.Dec{}, fmt.Errorf("division by zero")
+		}
+
+		return x.Quo(y), nil
+	}
+}
+
+// parseExpr parses a sum of terms.
+func (p *parser) parseExpr() (expression, error) {
+	x, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return x, nil
+		}
+
+		op := p.input[p.pos]
+		p.pos++
+
+		y, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		x = binary{op: op, x: x, y: y}
+	}
+}
+
+// parseTerm parses a product of factors.
+func (p *parser) parseTerm() (expression, error) {
+	x, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return x, nil
+		}
+
+		op := p.input[p.pos]
+		p.pos++
+
+		y, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		x = binary{op: op, x: x, y: y}
+	}
+}
+
+// parseFactor parses a number, a denom, a negated factor or a parenthesized expression.
+func (p *parser) parseFactor() (expression, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '-':
+		p.pos++
+		x, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+
+		return negation{x: x}, nil
+
+	case c == '(':
+		p.pos++
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+
+		p.pos++
+		return x, nil
+
+	case unicode.IsDigit(c) || c == '.':
+		token := p.scan(func(c rune) bool { return unicode.IsDigit(c) || c == '.' })
+		value, err := types.NewDecFromStr(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s: %w", token, err)
+		}
+
+		return number{value: value}, nil
+
+	case unicode.IsLetter(c):
+		token := p.scan(func(c rune) bool {
+			return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_'
+		})
+
+		return denomRef{denom: token}, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected %q", c)
+	}
+}
+
+// scan returns the longest token of runes matching the predicate.
+func (p *parser) scan(match func(rune) bool) string {
+	start := p.pos
+	for p.pos < len(p.input) && match(p.input[p.pos]) {
+		p.pos++
+	}
+
+	return string(p.input[start:p.pos])
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
@@ -0,0 +1,150 @@
+package relayer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	"github.com/cosmos/cosmos-sdk/telemetry"
+	"github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/ojo-network/cw-relayer/pkg/alert"
+	"github.com/ojo-network/cw-relayer/relayer/client"
+)
+
+// VerifyConfig defines the verification of relayed rates. If enabled, the relayer waits up to
+// Timeout for the relay tx to be included, and compares the rates, request id and resolve time
+// stored by the contract at the inclusion height with the relayed values.
+type VerifyConfig struct {
+	Enabled bool
+	Timeout time.Duration
+}
+
+// maxVerifications bounds the relay verifications in flight, verifications of
+// further relays are skipped until one completes.
+const maxVerifications = 4
+
+// refData is the contract response of a get_ref query.
+type refData struct {
+	Rate        string `json:"rate"`
+	ResolveTime string `json:"resolve_time"`
+	RequestID   string `json:"request_id"`
+}
+
+// startVerify verifies the relay in the background on a copy of the relayed rates,
+// the verification is skipped if maxVerifications are already in flight.
+func (r *Relayer) startVerify(ctx context.Context, txHash string, requestID uint64, resolveTime int64, rates types.DecCoins) {
+	select {
+	case r.verifying <- struct{}{}:
+	default:
+		telemetry.IncrCounter(1, "failure", "verify", "skipped")
+		r.logger.Warn().Str("tx_hash", txHash).Uint64("request id", requestID).Msg("relay verifications in flight, skipping verification")
+		return
+	}
+
+	rates = append(types.DecCoins(nil), rates...)
+	go func() {
+		defer func() { <-r.verifying }()
+		r.verifyRelay(ctx, txHash, requestID, resolveTime, rates)
+	}()
+}
+
+// verifyRelay checks the contract state after the relay tx is included,
+// and reports every symbol whose stored values do not match the relayed values.
+func (r *Relayer) verifyRelay(ctx context.Context, txHash string, requestID uint64, resolveTime int64, rates types.DecCoins) {
+	logger := r.logger.With().Str("tx_hash", txHash).Uint64("request id", requestID).Logger()
+
+	resp, err := r.relayerClient.WaitForTx(ctx, txHash, r.verify.Timeout)
+	if err != nil {
+		telemetry.IncrCounter(1, "failure", "verify")
+		logger.Err(err).Msg("relay verification failed")
+		return
+	}
+
+	if resp.Code != 0 {
+		telemetry.IncrCounter(1, "failure", "verify")
+		logger.Error().Uint32("tx_code", resp.Code).Msg("relay tx failed on chain")
+		return
+	}
+
+	queries, err := genRefQueries(r.contractAddress, rates)
+	if err != nil {
+		logger.Err(err).Msg("relay verification failed")
+		return
+	}
+
+	responses, err := r.relayerClient.BroadcastContractQueryAtHeight(ctx, r.queryTimeout, resp.Height, queries...)
+	if err != nil {
+		telemetry.IncrCounter(1, "failure", "verify")
+		logger.Err(err).Msg("relay verification query failed")
+		return
+	}
+
+	mismatches := 0
+	for _, response := range responses {
+		rate := rates[response.QueryType]
+		if err := checkRefData(response.QueryResponse.Data, rate, requestID, resolveTime); err != nil {
+			mismatches++
+			logger.Error().Err(err).Str("symbol", rate.Denom).Msg("contract state does not match relayed rate")
+		}
+	}
+
+	if mismatches > 0 {
+		telemetry.IncrCounter(float32(mismatches), "failure", "verify", "mismatch")
+		r.alert(AlertVerify, alert.SeverityCritical, "contract state does not match relayed rates", map[string]string{
+			"tx_hash":    txHash,
+			"request id": strconv.FormatUint(requestID, 10),
+			"mismatches": strconv.Itoa(mismatches),
+		})
+		return
+	}
+
+	logger.Debug().Int64("tx_height", resp.Height).Int("symbols", len(rates)).Msg("relay verified")
+}
+
+// genRefQueries returns a get_ref query for each rate, the query type is the index of the rate.
+func genRefQueries(contractAddress string, rates types.DecCoins) ([]client.SmartQuery, error) {
+	queries := make([]client.SmartQuery, len(rates))
+	for i, rate := range rates {
+		data, err := json.Marshal(rateMsg{Ref: symbol{Symbol: rate.Denom}})
+		if err != nil {
+			return nil, err
+		}
+
+		queries[i] = client.SmartQuery{
+			QueryType: i,
+			QueryMsg: wasmtypes.QuerySmartContractStateRequest{
+				Address:   contractAddress,
+				QueryData: data,
+			},
+		}
+	}
+
+	return queries, nil
+}
+
+// checkRefData returns an error if the stored ref data does not match the relayed rate.
+func checkRefData(data []byte, rate types.DecCoin, requestID uint64, resolveTime int64) error {
+	var ref refData
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return err
+	}
+
+	expectedRate := rate.Amount.Mul(RateFactor).TruncateInt().String()
+	if ref.Rate != expectedRate {
+		return fmt.Errorf("stored rate %s, relayed %s", ref.Rate, expectedRate)
+	}
+
+	if ref.RequestID != strconv.FormatUint(requestID, 10) {
+		return fmt.Errorf("stored request id %s, relayed %d", ref.RequestID, requestID)
+	}
+
+	if ref.ResolveTime != strconv.FormatInt(resolveTime, 10) {
+		return fmt.Errorf("stored resolve time %s, relayed %d", ref.ResolveTime, resolveTime)
+	}
+
+	return nil
+}